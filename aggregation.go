@@ -0,0 +1,157 @@
+package toyfloat
+
+import (
+	"math"
+	"sort"
+)
+
+// Sum returns the sum of encoded values.
+// It uses compensated summation, so the result does not depend
+// much on the order of the codes.
+func Sum(t *Type, codes []uint16) float64 {
+	table := t.decodeTable()
+
+	var s compensatedSum
+	for _, code := range codes {
+		s.add(table[t.index(code)])
+	}
+	return s.result()
+}
+
+// Mean returns the arithmetic mean of encoded values,
+// or NaN for an empty slice.
+func Mean(t *Type, codes []uint16) float64 {
+	if len(codes) == 0 {
+		return math.NaN()
+	}
+	return Sum(t, codes) / float64(len(codes))
+}
+
+// Variance returns the population variance of encoded values,
+// or NaN for an empty slice.
+func Variance(t *Type, codes []uint16) float64 {
+	if len(codes) == 0 {
+		return math.NaN()
+	}
+
+	table := t.decodeTable()
+	mean := Mean(t, codes)
+
+	var s compensatedSum
+	for _, code := range codes {
+		d := table[t.index(code)] - mean
+		s.add(d * d)
+	}
+	return s.result() / float64(len(codes))
+}
+
+// Min returns the minimum of encoded values, or NaN for an empty slice.
+// It compares the comparable forms, so nothing is decoded but the result.
+// As in math.Min, -0 is less than +0.
+// NaN codes are skipped, as in Histogram, so the result is NaN
+// only if there are no other codes.
+func Min(t *Type, codes []uint16) float64 {
+	nan := nanCodeTable(t)
+
+	found := false
+	best := uint16(0)
+	for _, code := range codes {
+		if (nil != nan) && math.IsNaN(nan[t.index(code)]) {
+			continue
+		} else if c := codeToComparable(code, t); !found || (c < best) {
+			best = c
+			found = true
+		}
+	}
+
+	if !found {
+		return math.NaN()
+	}
+	return t.Decode(comparableToCode(best, t))
+}
+
+// Max returns the maximum of encoded values, or NaN for an empty slice.
+// As in math.Max, +0 is greater than -0.
+// NaN codes are skipped, as in Min.
+func Max(t *Type, codes []uint16) float64 {
+	nan := nanCodeTable(t)
+
+	found := false
+	best := uint16(0)
+	for _, code := range codes {
+		if (nil != nan) && math.IsNaN(nan[t.index(code)]) {
+			continue
+		} else if c := codeToComparable(code, t); !found || (c > best) {
+			best = c
+			found = true
+		}
+	}
+
+	if !found {
+		return math.NaN()
+	}
+	return t.Decode(comparableToCode(best, t))
+}
+
+// nanCodeTable returns the decode table of types with NaN codes,
+// or nil for the others.
+func nanCodeTable(t *Type) []float64 {
+	if t.nonFinite || (NegativeZeroMarker == t.zeroMode) ||
+		((nil != t.data) && (nil != t.data.reserved)) {
+
+		return t.decodeTable()
+	}
+	return nil
+}
+
+// Histogram counts encoded values in bins between ascending edges.
+// Bin i is [edges[i], edges[i+1]), the last one includes its right edge.
+// Values outside of the edges are not counted.
+// Both zeros have the same value, so they always share a bin.
+// NaN codes (see WithNonFinite, WithReserved and NegativeZeroMarker)
+// are not counted either.
+func Histogram(t *Type, codes []uint16, edges []float64) []int {
+	if len(edges) < 2 {
+		return nil
+	}
+
+	counts := make([]int, len(edges)-1)
+	table := t.decodeTable()
+	last := edges[len(edges)-1]
+
+	for _, code := range codes {
+		v := table[t.index(code)]
+		if math.IsNaN(v) || (v < edges[0]) || (v > last) {
+			continue
+		} else if v == last {
+			counts[len(counts)-1]++
+			continue
+		}
+
+		// The first edge that is greater than v closes its bin.
+		i := sort.Search(len(edges), func(i int) bool {
+			return edges[i] > v
+		})
+		counts[i-1]++
+	}
+	return counts
+}
+
+// compensatedSum is the Neumaier variant of Kahan summation.
+type compensatedSum struct {
+	sum, compensation float64
+}
+
+func (s *compensatedSum) add(x float64) {
+	t := s.sum + x
+	if math.Abs(s.sum) >= math.Abs(x) {
+		s.compensation += (s.sum - t) + x
+	} else {
+		s.compensation += (x - t) + s.sum
+	}
+	s.sum = t
+}
+
+func (s *compensatedSum) result() float64 {
+	return s.sum + s.compensation
+}
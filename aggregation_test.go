@@ -0,0 +1,240 @@
+package toyfloat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func getAggregationSample(tf *Type, n int) ([]uint16, []float64) {
+	r := rand.New(rand.NewSource(42))
+	codes := make([]uint16, n)
+	values := make([]float64, n)
+	for i := range codes {
+		codes[i] = uint16(r.Intn(int(tf.bitmask) + 1))
+		values[i] = tf.Decode(codes[i])
+	}
+	return codes, values
+}
+
+func TestSumAndMean(t *testing.T) {
+	tf := makeTypeX4(12, true, t)
+	codes, values := getAggregationSample(&tf, 10000)
+
+	expected := 0.0
+	for _, v := range values {
+		expected += v
+	}
+
+	const eps = 1e-9
+
+	if s := Sum(&tf, codes); math.Abs(s-expected) > eps {
+		t.Fatalf("sum: %f != %f", s, expected)
+	}
+
+	mean := expected / float64(len(values))
+	if m := Mean(&tf, codes); math.Abs(m-mean) > eps {
+		t.Fatalf("mean: %f != %f", m, mean)
+	}
+
+	if !math.IsNaN(Mean(&tf, nil)) {
+		t.Fatalf("mean of nothing must be NaN")
+	}
+
+	if Sum(&tf, nil) != 0 {
+		t.Fatalf("sum of nothing must be zero")
+	}
+}
+
+func TestCompensatedSum(t *testing.T) {
+	tf := makeTypeX4(16, true, t)
+
+	// Naive summation loses all the small values.
+	codes := []uint16{tf.Encode(255)}
+	small := tf.Encode(0.000015)
+	for i := 0; i < 100000; i++ {
+		codes = append(codes, small)
+	}
+	codes = append(codes, tf.Encode(-255))
+
+	expected := 100000 * tf.Decode(small)
+	if s := Sum(&tf, codes); math.Abs(s-expected) > 1e-12 {
+		t.Fatalf("%.15f != %.15f", s, expected)
+	}
+}
+
+func TestVariance(t *testing.T) {
+	tf := makeTypeX3(8, true, t)
+	codes, values := getAggregationSample(&tf, 1000)
+
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	expected := 0.0
+	for _, v := range values {
+		expected += (v - mean) * (v - mean)
+	}
+	expected /= float64(len(values))
+
+	if v := Variance(&tf, codes); math.Abs(v-expected) > 1e-9 {
+		t.Fatalf("%f != %f", v, expected)
+	}
+
+	one := []uint16{tf.Encode(1), tf.Encode(1)}
+	if v := Variance(&tf, one); v != 0 {
+		t.Fatalf("%f != 0", v)
+	}
+
+	if !math.IsNaN(Variance(&tf, nil)) {
+		t.Fatalf("variance of nothing must be NaN")
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	tf := makeTypeX4(12, true, t)
+	codes, values := getAggregationSample(&tf, 10000)
+
+	min, max := values[0], values[0]
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+
+	if m := Min(&tf, codes); m != min {
+		t.Fatalf("min: %f != %f", m, min)
+	}
+
+	if m := Max(&tf, codes); m != max {
+		t.Fatalf("max: %f != %f", m, max)
+	}
+
+	// Extra bits are ignored.
+	extra := []uint16{0xF000 | tf.Encode(-3), 0xF000 | tf.Encode(4)}
+	if (Min(&tf, extra) != tf.Decode(extra[0])) ||
+		(Max(&tf, extra) != tf.Decode(extra[1])) {
+		t.Fatalf("extra bits are not ignored")
+	}
+
+	zeros := []uint16{0x0, tf.minus}
+	if !math.Signbit(Min(&tf, zeros)) || math.Signbit(Max(&tf, zeros)) {
+		t.Fatalf("min must be -0 and max must be +0")
+	}
+
+	if !math.IsNaN(Min(&tf, nil)) || !math.IsNaN(Max(&tf, nil)) {
+		t.Fatalf("NaN expected")
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	tf := makeTypeX4(12, true, t)
+
+	codes := []uint16{
+		tf.Encode(-300), // saturated to the minimum
+		tf.Encode(-1),
+		0x0,
+		tf.minus, // -0
+		tf.Encode(0.5),
+		tf.Encode(1),
+		tf.Encode(2),
+		tf.Encode(3),
+	}
+
+	edges := []float64{-1, 0, 1, tf.Decode(tf.Encode(2))}
+	expected := []int{1, 3, 2}
+
+	result := Histogram(&tf, codes, edges)
+	if len(result) != len(expected) {
+		t.Fatalf("%d bins", len(result))
+	}
+
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Fatalf("bin %d: %d != %d", i, result[i], expected[i])
+		}
+	}
+
+	if Histogram(&tf, codes, []float64{1}) != nil {
+		t.Fatalf("a single edge makes no bins")
+	}
+}
+
+func TestMinMaxNaN(t *testing.T) {
+	types := []Type{
+		makeTypeX4(12, true, t, WithNonFinite()),
+		makeTypeX4(12, true, t, WithReserved(0x7FF)),
+		makeTypeX4(12, true, t, WithNegativeZero(NegativeZeroMarker)),
+	}
+
+	for _, tf := range types {
+		nan := tf.Encode(math.NaN())
+		if NegativeZeroMarker == tf.zeroMode {
+			nan = tf.minus
+		} else if 0 != len(tf.Reserved()) {
+			nan = uint16(tf.Reserved()[0])
+		}
+
+		low, high := tf.Encode(-1), tf.Encode(1)
+		codes := []uint16{nan, high, nan, low, nan}
+		if min, max := Min(&tf, codes), Max(&tf, codes); min != tf.Decode(low) || max != tf.Decode(high) {
+			t.Fatalf("%s: %v, %v", tf.Spec(), min, max)
+		}
+
+		codes = []uint16{nan, nan}
+		if !math.IsNaN(Min(&tf, codes)) || !math.IsNaN(Max(&tf, codes)) {
+			t.Fatalf("%s: NaN expected", tf.Spec())
+		}
+	}
+
+	tf := types[0]
+	codes := []uint16{tf.Encode(math.NaN()), tf.Encode(math.Inf(+1)), tf.Encode(math.Inf(-1))}
+	if min, max := Min(&tf, codes), Max(&tf, codes); !math.IsInf(min, -1) || !math.IsInf(max, +1) {
+		t.Fatalf("%v, %v", min, max)
+	}
+}
+
+func TestHistogramNaN(t *testing.T) {
+	types := []Type{
+		makeTypeX4(12, true, t, WithNonFinite()),
+		makeTypeX4(12, true, t, WithReserved(0x7FF)),
+		makeTypeX4(12, true, t, WithNegativeZero(NegativeZeroMarker)),
+	}
+	edges := []float64{-1, 0, 1}
+
+	for _, tf := range types {
+		nan := tf.Encode(math.NaN())
+		if NegativeZeroMarker == tf.zeroMode {
+			nan = tf.minus
+		} else if 0 != len(tf.Reserved()) {
+			nan = uint16(tf.Reserved()[0])
+		}
+		if !math.IsNaN(tf.Decode(nan)) {
+			t.Fatalf("%s: 0x%X is not NaN", tf.Spec(), nan)
+		}
+
+		codes := []uint16{nan, tf.Encode(0.5), nan}
+		if counts := Histogram(&tf, codes, edges); counts[0] != 0 || counts[1] != 1 {
+			t.Fatalf("%s: %v", tf.Spec(), counts)
+		}
+	}
+}
+
+func BenchmarkSum(b *testing.B) {
+	tf, e := NewTypeX4(12, true)
+	if e != nil {
+		b.Fatal(e)
+	}
+
+	codes := make([]uint16, 10000)
+	for i := range codes {
+		codes[i] = uint16(i)
+	}
+
+	r := 0.0
+	for i := 0; i < b.N; i++ {
+		r = Sum(&tf, codes)
+	}
+	intResult = int(r)
+}
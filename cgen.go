@@ -0,0 +1,825 @@
+package toyfloat
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// CHeader selects what WriteC generates.
+type CHeader struct {
+	// Prefix of the names, such as "x12" for x12_encode.
+	Prefix string
+
+	// FractionBits of the fixed-point numbers, which are int32_t
+	// with this many bits after the point, from 1 to 24.
+	// The fixed-point variants are only generated, if it is not zero.
+	FractionBits uint8
+
+	// Tables adds lookup tables with the decoded values of all codes.
+	Tables bool
+}
+
+// WriteC writes a self-contained C99 header with the functions:
+//
+//	uint16_t x12_encode(float v);
+//	float x12_decode(uint16_t code);
+//
+// Unlike Encode and Decode, they compute in single precision,
+// so the values may differ slightly, and so may the codes
+// of the values close to the boundaries of the rounding bins.
+//
+// With FractionBits, there are fixed-point variants,
+// which use no floating-point arithmetic at all:
+//
+//	uint16_t x12_encode_fixed(int32_t v);
+//	int32_t x12_decode_fixed(uint16_t code);
+//
+// They decode NaN to zero, and infinities to INT32_MAX and INT32_MIN,
+// which encode back to infinities. The maximum value of the type
+// must fit into the fixed-point numbers.
+//
+// With Tables, x12_decode_table and x12_decode_fixed_table
+// look the results up.
+//
+// Invalid headers result in ErrInvalidCHeader.
+func WriteC(w io.Writer, t Type, h CHeader) error {
+	if !t.IsValid() {
+		return ErrInvalidType
+	}
+
+	c, err := newCConstants(&t, &h)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	guard := strings.ToUpper(h.Prefix) + "_TOYFLOAT_H"
+	fmt.Fprintf(&b, "/* Code generated by toyfloat.WriteC; DO NOT EDIT. */\n")
+	fmt.Fprintf(&b, "/* The type %s. */\n\n", t.Spec())
+	fmt.Fprintf(&b, "#ifndef %s\n#define %s\n\n", guard, guard)
+	fmt.Fprintf(&b, "#include <math.h>\n#include <stdint.h>\n\n")
+
+	if 0 != h.FractionBits {
+		fmt.Fprintf(&b, "#define %s_FRACTION_BITS %d\n\n", guard[:len(h.Prefix)], h.FractionBits)
+	}
+
+	writeCLayout(&b, &t, h.Prefix)
+	writeCFloat(&b, &t, h.Prefix)
+	if 0 != h.FractionBits {
+		writeCFixed(&b, &t, &h, c)
+	}
+	if h.Tables {
+		writeCTables(&b, &t, &h)
+	}
+
+	fmt.Fprintf(&b, "#endif\n")
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+// WriteCTest writes a C program, that checks the header
+// with the given include name against golden vectors of this package.
+// It prints the failures and returns 1, if there are any.
+// The header must be generated by WriteC with the same arguments.
+func WriteCTest(w io.Writer, t Type, h CHeader, include string) error {
+	if !t.IsValid() {
+		return ErrInvalidType
+	} else if _, err := newCConstants(&t, &h); err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	writeCTest(&b, &t, &h, include)
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// ----------------
+
+// cConstants are the constants of the fixed-point variants.
+//
+// Decoding computes ((2^M + (b-1)m) * scale[x] - offset) >> guard,
+// where scale[x] is b^x * c / 2^M, and offset is a*c,
+// both with fraction+guard bits after the point.
+//
+// Encoding computes the inner value a + |v|*(1-a) with inner bits
+// after the point, where 1-a is the ratio with 32 bits after the point.
+// Then it finds the exponent by the boundaries, and divides by the power.
+type cConstants struct {
+	guard, inner uint
+	scale        []int64
+	offset       int64
+	maxFixed     int64
+
+	ratio, a   uint64
+	boundaries []uint64 // from the exponent 1
+	powers     []uint64
+}
+
+func newCConstants(t *Type, h *CHeader) (*cConstants, error) {
+	if !isCIdentifier(h.Prefix) {
+		return nil, ErrInvalidCHeader
+	} else if 0 == h.FractionBits {
+		return nil, nil
+	} else if h.FractionBits > 24 {
+		return nil, ErrInvalidCHeader
+	}
+
+	f := uint(h.FractionBits)
+	maxFixed := math.Floor(t.maxValue * math.Ldexp(1, int(f)))
+	if maxFixed >= math.MaxInt32-1 {
+		return nil, ErrInvalidCHeader
+	}
+
+	b := float64(t.xBase)
+	a := cPower(t, 0)
+	one := big.NewFloat(1).SetPrec(cPrecision)
+	cc := new(big.Float).SetPrec(cPrecision).Quo(one, new(big.Float).Sub(one, a))
+
+	// (2^M + (b-1)m) * scale[x] < b^(maxX+1) * c * 2^(fraction+guard) < 2^62
+	top, _ := new(big.Float).Mul(cPower(t, len(t.data.scale)-1), cc).Float64()
+	guard := 62 - int(math.Ceil(math.Log2(b*top))) - int(f)
+	if guard < 16 {
+		return nil, ErrInvalidCHeader
+	} else if guard > 40 {
+		guard = 40
+	}
+
+	c := &cConstants{guard: uint(guard), maxFixed: int64(maxFixed)}
+
+	// |v| * ratio >> (M+1) < 2^(31+inner-fraction), shifted by M, must fit too.
+	c.inner = f + 31 - uint(t.mSize)
+
+	decodeUnit := new(big.Float).SetMantExp(one, int(f+c.guard)-int(t.mSize))
+	for x := range t.data.scale {
+		scale := new(big.Float).SetPrec(cPrecision).Mul(cPower(t, x), cc)
+		c.scale = append(c.scale, cRound(scale.Mul(scale, decodeUnit)))
+	}
+
+	offset := new(big.Float).SetPrec(cPrecision).Mul(a, cc)
+	c.offset = cRound(offset.SetMantExp(offset, int(f+c.guard)))
+
+	ratio := new(big.Float).SetPrec(cPrecision).Sub(one, a)
+	c.ratio = uint64(cRound(ratio.SetMantExp(ratio, 32)))
+
+	innerA := new(big.Float).SetPrec(cPrecision).SetMantExp(a, int(c.inner))
+	c.a = uint64(cRound(innerA))
+
+	// The smallest step must be many units long,
+	// so that the rounding is the same as in Go.
+	if c.a>>t.mSize < 1<<8 {
+		return nil, ErrInvalidCHeader
+	}
+
+	// xBoundary = 1 + (b-1) * (2^M - 0.5) / 2^M
+	xb := new(big.Float).SetPrec(cPrecision).SetFloat64(math.Ldexp(1, int(t.mSize)) - 0.5)
+	xb.Mul(xb, big.NewFloat(b-1))
+	xb.SetMantExp(xb, -int(t.mSize))
+	xb.Add(xb, one)
+
+	for x := range t.data.scale {
+		power := new(big.Float).SetPrec(cPrecision).SetMantExp(cPower(t, x), int(c.inner))
+		c.powers = append(c.powers, uint64(cRound(power)))
+
+		if x > 0 {
+			boundary := new(big.Float).SetPrec(cPrecision).Mul(xb, cPower(t, x-1))
+			boundary.SetMantExp(boundary, int(c.inner))
+			c.boundaries = append(c.boundaries, uint64(cRound(boundary)))
+		}
+	}
+	return c, nil
+}
+
+const cPrecision = 256
+
+// cPower returns b^(minX+x).
+func cPower(t *Type, x int) *big.Float {
+	r := big.NewFloat(1).SetPrec(cPrecision)
+	base := big.NewFloat(float64(t.xBase)).SetPrec(cPrecision)
+
+	power := t.minX + x
+	for i := 0; i < power; i++ {
+		r.Mul(r, base)
+	}
+	for i := 0; i > power; i-- {
+		r.Quo(r, base)
+	}
+	return r
+}
+
+func cRound(f *big.Float) int64 {
+	f.Add(f, big.NewFloat(0.5))
+	r, _ := f.Int64()
+	return r
+}
+
+func isCIdentifier(s string) bool {
+	if "" == s || isDigit(s[0]) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isLetter(s[i]) && !isDigit(s[i]) && (s[i] != '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// cFloat prints a float literal, that is exactly the float32 of the value.
+func cFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NAN"
+	case math.IsInf(f, +1):
+		return "INFINITY"
+	case math.IsInf(f, -1):
+		return "-INFINITY"
+	}
+
+	s := strconv.FormatFloat(float64(float32(f)), 'g', -1, 32)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s + "f"
+}
+
+// cFixed returns the value as a fixed-point number.
+func cFixed(f float64, fraction uint8) int64 {
+	switch {
+	case math.IsNaN(f):
+		return 0
+	case math.IsInf(f, +1):
+		return math.MaxInt32
+	case math.IsInf(f, -1):
+		return math.MinInt32
+	}
+	return int64(math.Round(math.Ldexp(f, int(fraction))))
+}
+
+// writeCLayout writes the conversions of sign–magnitude "s x m" codes
+// from and to the layout, two's complement and protection.
+func writeCLayout(b *bytes.Buffer, t *Type, p string) {
+	fmt.Fprintf(b, "/* Sign–magnitude \"s x m\" codes to the layout. */\n")
+	fmt.Fprintf(b, "static uint16_t %s_finish(uint16_t code)\n{\n", p)
+
+	if t.twos {
+		fmt.Fprintf(b, "    /* Two's complement. */\n")
+		fmt.Fprintf(b, "    if (0 == (code & 0x%Xu)) {\n        code |= 0x%Xu;\n    } else ",
+			t.minus, t.minus)
+		switch t.zeroMode {
+		case KeepNegativeZero:
+			fmt.Fprintf(b, "{\n        code = (uint16_t)~code;\n    }\n")
+		case CanonicalZero:
+			fmt.Fprintf(b, "{\n        code = (uint16_t)(~code + 1u);\n    }\n")
+		case NegativeZeroMarker:
+			fmt.Fprintf(b, "if (code == 0x%Xu) {\n        code = 0;\n    } else {\n"+
+				"        code = (uint16_t)(~code + 1u);\n    }\n", t.minus)
+		}
+		fmt.Fprintf(b, "    code = (uint16_t)((code & 0x%Xu) ^ 0x%Xu);\n", t.bitmask, t.minus)
+		fmt.Fprintf(b, "    if (0 != (code & 0x%Xu)) {\n        code |= 0x%Xu;\n    }\n",
+			t.minus, ^t.bitmask)
+	}
+
+	if t.rearranged {
+		fmt.Fprintf(b, "    /* The layout %s. */\n", t.layout)
+		fmt.Fprintf(b, "    {\n        uint16_t x = code & 0x%Xu;\n", t.bitmask)
+		fmt.Fprintf(b, "        code = (uint16_t)((((x >> %d) & 0x%Xu) << %d) | ((x & 0x%Xu) << %d));\n",
+			t.mSize, t.xMask, t.xShift, t.mMask, t.mShift)
+		if 0 != t.minus {
+			fmt.Fprintf(b, "        if (0 != (x & 0x%Xu)) {\n            code |= 0x%Xu;\n        }\n",
+				t.minus, uint16(1)<<t.sShift)
+		}
+		fmt.Fprintf(b, "        code = (uint16_t)(code << %d);\n    }\n", t.align)
+	}
+
+	if NoProtection != t.protection {
+		fmt.Fprintf(b, "    /* The check bits of %s. */\n", t.protection)
+		fmt.Fprintf(b, "    {\n        static const uint16_t columns[%d] = {", t.length)
+		for i := uint8(0); i < t.length; i++ {
+			if i > 0 {
+				fmt.Fprintf(b, ", ")
+			}
+			fmt.Fprintf(b, "0x%Xu", checkBits(uint16(1)<<i, t))
+		}
+		fmt.Fprintf(b, "};\n")
+		fmt.Fprintf(b, "        uint16_t value = (uint16_t)((code >> %d) & 0x%Xu);\n",
+			t.align, t.bitmask)
+		fmt.Fprintf(b, "        uint16_t check = 0;\n        int i;\n")
+		fmt.Fprintf(b, "        for (i = 0; i < %d; i++) {\n", t.length)
+		fmt.Fprintf(b, "            if (0 != ((value >> i) & 1u)) {\n"+
+			"                check ^= columns[i];\n            }\n        }\n")
+		fmt.Fprintf(b, "        code = (uint16_t)((code & 0x%Xu) | ((check << %d) & 0x%Xu));\n    }\n",
+			t.bitmask<<t.align, t.tagShift(), t.spareMask())
+	}
+
+	fmt.Fprintf(b, "    return code;\n}\n\n")
+
+	fmt.Fprintf(b, "/* Codes in the layout to sign–magnitude \"s x m\" without extra bits. */\n")
+	fmt.Fprintf(b, "static uint16_t %s_start(uint16_t code)\n{\n", p)
+	if t.rearranged {
+		fmt.Fprintf(b, "    uint16_t p = (uint16_t)((code >> %d) & 0x%Xu);\n", t.align, t.bitmask)
+		fmt.Fprintf(b, "    uint16_t x = (uint16_t)((((p >> %d) & 0x%Xu) << %d) | ((p >> %d) & 0x%Xu)",
+			t.xShift, t.xMask, t.mSize, t.mShift, t.mMask)
+		if 0 != t.minus {
+			fmt.Fprintf(b, " | (((p >> %d) & 1u) << %d)", t.sShift, t.length-1)
+		}
+		fmt.Fprintf(b, ");\n")
+	} else {
+		fmt.Fprintf(b, "    uint16_t x = code & 0x%Xu;\n", t.bitmask)
+	}
+
+	if t.twos {
+		fmt.Fprintf(b, "    uint16_t c = (uint16_t)((x ^ 0x%Xu) & 0x%Xu);\n", t.minus, t.bitmask)
+		fmt.Fprintf(b, "    if (0 == (c & 0x%Xu)) {\n", t.minus)
+		switch t.zeroMode {
+		case KeepNegativeZero:
+			fmt.Fprintf(b, "        x = (uint16_t)~c;\n")
+		case CanonicalZero:
+			fmt.Fprintf(b, "        if (0 == c) {\n            c = 1;\n        }\n"+
+				"        x = (uint16_t)~(c - 1u);\n")
+		case NegativeZeroMarker:
+			fmt.Fprintf(b, "        x = (0 == c) ? 0x%Xu : (uint16_t)~(c - 1u);\n", t.minus)
+		}
+		fmt.Fprintf(b, "    } else {\n        x = (uint16_t)(c & 0x%Xu);\n    }\n", ^t.minus)
+		fmt.Fprintf(b, "    x &= 0x%Xu;\n", t.bitmask)
+	}
+	fmt.Fprintf(b, "    return x;\n}\n\n")
+}
+
+// writeCSpecial returns early for reserved codes, zeros and non-finite values.
+func writeCSpecial(b *bytes.Buffer, t *Type, nan, posInf, negInf, zero string) {
+	if nil != t.data.reserved {
+		fmt.Fprintf(b, "    switch (x) {\n")
+		for code, reserved := range t.data.reserved.is {
+			if reserved {
+				fmt.Fprintf(b, "    case 0x%Xu:\n", code)
+			}
+		}
+		fmt.Fprintf(b, "        return %s;\n    }\n", nan)
+	}
+
+	if (KeepNegativeZero != t.zeroMode) && (0 != t.minus) {
+		result := zero
+		if NegativeZeroMarker == t.zeroMode {
+			result = nan
+		}
+		fmt.Fprintf(b, "    if (x == 0x%Xu) {\n        return %s;\n    }\n", t.minus, result)
+	}
+
+	if t.nonFinite {
+		fmt.Fprintf(b, "    if ((x & 0x%Xu) == 0x%Xu) {\n", t.infCode, t.infCode)
+		if 0 != t.minus {
+			fmt.Fprintf(b, "        return (0 != (x & 0x%Xu)) ? %s : %s;\n    }\n",
+				t.minus, negInf, posInf)
+		} else {
+			fmt.Fprintf(b, "        return %s;\n    }\n", posInf)
+		}
+		fmt.Fprintf(b, "    if ((x & 0x%Xu) == 0x%Xu) {\n        return %s;\n    }\n",
+			t.infCode, t.nanCode, nan)
+	}
+}
+
+func writeCFloat(b *bytes.Buffer, t *Type, p string) {
+	a := t.data.scale[0]
+
+	fmt.Fprintf(b, "static const float %s_scale[%d] = {", p, len(t.data.scale))
+	for i, scale := range t.data.scale {
+		if i > 0 {
+			fmt.Fprintf(b, ", ")
+		}
+		fmt.Fprintf(b, "%s", cFloat(scale))
+	}
+	fmt.Fprintf(b, "};\n\n")
+
+	// The magnitude.
+	fmt.Fprintf(b, "static uint16_t %s_magnitude(float inner)\n{\n", p)
+	fmt.Fprintf(b, "    uint16_t x = 0;\n    float m;\n")
+	boundaries := make([]string, 0, len(t.data.scale))
+	for _, scale := range t.data.scale[:len(t.data.scale)-1] {
+		boundaries = append(boundaries, cFloat(t.xBoundary*scale))
+	}
+	writeCExponentSearch(b, boundaries)
+	fmt.Fprintf(b, "    m = %s * inner / %s_scale[x] - %s + 0.49999997f;\n",
+		cFloat(t.esFactor), p, cFloat(t.esFactor))
+	fmt.Fprintf(b, "    if (m < 0.0f) {\n        m = 0.0f;\n    }\n")
+	fmt.Fprintf(b, "    /* A rounding error carries into the exponent. */\n")
+	fmt.Fprintf(b, "    x = (uint16_t)((x << %d) + (uint16_t)m);\n", t.mSize)
+	fmt.Fprintf(b, "    return (x > 0x%Xu) ? 0x%Xu : x;\n}\n\n", t.maxMagnitude, t.maxMagnitude)
+
+	// The encoder.
+	fmt.Fprintf(b, "static uint16_t %s_encode(float v)\n{\n", p)
+	fmt.Fprintf(b, "    uint16_t code;\n")
+	fmt.Fprintf(b, "    if (v != v) {\n        code = 0x%Xu;\n", t.nanCode)
+	fmt.Fprintf(b, "    } else if (v > %s) {\n", cFloat(t.maxValue))
+	if t.nonFinite {
+		fmt.Fprintf(b, "        code = (v == INFINITY) ? 0x%Xu : 0x%Xu;\n", t.infCode, t.maxMagnitude)
+	} else {
+		fmt.Fprintf(b, "        code = 0x%Xu;\n", t.maxMagnitude)
+	}
+
+	if 0 == t.minus {
+		fmt.Fprintf(b, "    } else if (v < 0.0f) {\n        code = 0;\n")
+	} else {
+		fmt.Fprintf(b, "    } else if (v < %s) {\n", cFloat(t.minValue))
+		if t.nonFinite {
+			fmt.Fprintf(b, "        code = (v == -INFINITY) ? 0x%Xu : 0x%Xu;\n",
+				t.minus|t.infCode, t.minus|t.maxMagnitude)
+		} else {
+			fmt.Fprintf(b, "        code = 0x%Xu;\n", t.minus|t.maxMagnitude)
+		}
+		fmt.Fprintf(b, "    } else if (v < 0.0f) {\n")
+		fmt.Fprintf(b, "        code = (uint16_t)(0x%Xu | %s_magnitude(%s - v * %s));\n",
+			t.minus, p, cFloat(a), cFloat(1-a))
+	}
+	fmt.Fprintf(b, "    } else {\n")
+	fmt.Fprintf(b, "        code = %s_magnitude(%s + v * %s);\n    }\n", p, cFloat(a), cFloat(1-a))
+
+	writeCZeroAndReserved(b, t, func(v float64) string { return cFloat(v) }, "float")
+	fmt.Fprintf(b, "    return %s_finish(code);\n}\n\n", p)
+
+	// The decoder.
+	fmt.Fprintf(b, "static float %s_decode(uint16_t code)\n{\n", p)
+	fmt.Fprintf(b, "    uint16_t x = %s_start(code);\n    float v;\n", p)
+	writeCSpecial(b, t, "NAN", "INFINITY", "-INFINITY", "0.0f")
+	fmt.Fprintf(b, "    v = ((1.0f + (float)(x & 0x%Xu) * %s) * %s_scale[(x >> %d) & 0x%Xu] - %s) * %s;\n",
+		t.mMask, cFloat(t.dsFactor), p, t.mSize, t.xMask, cFloat(a), cFloat(1/(1-a)))
+	fmt.Fprintf(b, "    if ((1.0f - 1e-6f < v) && (v < 1.0f + 1e-6f)) {\n        v = 1.0f;\n    }\n")
+	if 0 != t.minus {
+		fmt.Fprintf(b, "    return (0 != (x & 0x%Xu)) ? -v : v;\n}\n\n", t.minus)
+	} else {
+		fmt.Fprintf(b, "    return v;\n}\n\n")
+	}
+}
+
+// writeCExponentSearch writes the choice of the exponent x of the inner value,
+// which is at least the boundary x-1.
+func writeCExponentSearch(b *bytes.Buffer, boundaries []string) {
+	prefix := "    "
+	for x := len(boundaries); x > 0; x-- {
+		fmt.Fprintf(b, "%sif (inner >= %s) {\n        x = %d;\n", prefix, boundaries[x-1], x)
+		prefix = "    } else "
+	}
+	if len(boundaries) > 0 {
+		fmt.Fprintf(b, "    }\n")
+	}
+}
+
+// writeCZeroAndReserved writes the end of an encoder, which makes
+// the code of -0 positive, and replaces reserved codes with the nearest
+// free ones. The values are printed by the function, and the type
+// is the type of v.
+func writeCZeroAndReserved(b *bytes.Buffer, t *Type, value func(float64) string, vType string) {
+	if (KeepNegativeZero != t.zeroMode) && (0 != t.minus) {
+		fmt.Fprintf(b, "    if (code == 0x%Xu) {\n        code = 0;\n    }\n", t.minus)
+	}
+
+	r := t.data.reserved
+	if nil == r {
+		return
+	}
+
+	fmt.Fprintf(b, "    switch (code) {\n")
+	for code, reserved := range r.is {
+		if !reserved {
+			continue
+		}
+
+		rank := int(r.rank[toComparable(uint16(code), t)])
+		fmt.Fprintf(b, "    case 0x%Xu:\n", code)
+
+		if rank == 0 {
+			fmt.Fprintf(b, "        code = 0x%Xu;\n", fromComparable(r.free[0], t)&t.bitmask)
+		} else if rank == len(r.free) {
+			fmt.Fprintf(b, "        code = 0x%Xu;\n", fromComparable(r.free[rank-1], t)&t.bitmask)
+		} else {
+			below := fromComparable(r.free[rank-1], t) & t.bitmask
+			above := fromComparable(r.free[rank], t) & t.bitmask
+			fmt.Fprintf(b, "        {\n")
+			fmt.Fprintf(b, "            %s below = %s - v, above = %s - v;\n", vType,
+				value(decodeNumber(below, t)), value(decodeNumber(above, t)))
+			fmt.Fprintf(b, "            code = ((below < 0 ? -below : below) < (above < 0 ? -above : above))"+
+				" ? 0x%Xu : 0x%Xu;\n        }\n", below, above)
+		}
+		fmt.Fprintf(b, "        break;\n")
+	}
+	fmt.Fprintf(b, "    }\n")
+}
+
+func writeCFixed(b *bytes.Buffer, t *Type, h *CHeader, c *cConstants) {
+	p := h.Prefix
+
+	fmt.Fprintf(b, "static const int64_t %s_fixed_scale[%d] = {", p, len(c.scale))
+	for i, scale := range c.scale {
+		if i > 0 {
+			fmt.Fprintf(b, ", ")
+		}
+		fmt.Fprintf(b, "%d", scale)
+	}
+	fmt.Fprintf(b, "};\n\n")
+
+	fmt.Fprintf(b, "static const uint64_t %s_fixed_power[%d] = {", p, len(c.powers))
+	for i, power := range c.powers {
+		if i > 0 {
+			fmt.Fprintf(b, ", ")
+		}
+		fmt.Fprintf(b, "%du", power)
+	}
+	fmt.Fprintf(b, "};\n\n")
+
+	// The magnitude.
+	fmt.Fprintf(b, "static uint16_t %s_fixed_magnitude(uint64_t inner)\n{\n", p)
+	fmt.Fprintf(b, "    uint16_t x = 0;\n    uint64_t m = 0;\n")
+	boundaries := make([]string, 0, len(c.boundaries))
+	for _, boundary := range c.boundaries {
+		boundaries = append(boundaries, strconv.FormatUint(boundary, 10)+"u")
+	}
+	writeCExponentSearch(b, boundaries)
+	fmt.Fprintf(b, "    if (inner > %s_fixed_power[x]) {\n", p)
+	fmt.Fprintf(b, "        uint64_t d = %du * %s_fixed_power[x];\n", t.xBase-1, p)
+	fmt.Fprintf(b, "        /* Half is rounded down. */\n")
+	fmt.Fprintf(b, "        m = (((inner - %s_fixed_power[x]) << %d) + (d - 1u) / 2u) / d;\n",
+		p, t.mSize)
+	fmt.Fprintf(b, "    }\n")
+	fmt.Fprintf(b, "    /* A rounding error carries into the exponent. */\n")
+	fmt.Fprintf(b, "    m += (uint64_t)x << %d;\n", t.mSize)
+	fmt.Fprintf(b, "    return (m > 0x%Xu) ? 0x%Xu : (uint16_t)m;\n}\n\n", t.maxMagnitude, t.maxMagnitude)
+
+	// The encoder.
+	fmt.Fprintf(b, "static uint16_t %s_encode_fixed(int32_t v)\n{\n", p)
+	fmt.Fprintf(b, "    uint16_t code;\n")
+	fmt.Fprintf(b, "    if (v > %d) {\n", c.maxFixed)
+	if t.nonFinite {
+		fmt.Fprintf(b, "        code = (v == INT32_MAX) ? 0x%Xu : 0x%Xu;\n", t.infCode, t.maxMagnitude)
+	} else {
+		fmt.Fprintf(b, "        code = 0x%Xu;\n", t.maxMagnitude)
+	}
+
+	if 0 == t.minus {
+		fmt.Fprintf(b, "    } else if (v < 0) {\n        code = 0;\n")
+	} else {
+		fmt.Fprintf(b, "    } else if (v < -%d) {\n", c.maxFixed)
+		if t.nonFinite {
+			fmt.Fprintf(b, "        code = (v == INT32_MIN) ? 0x%Xu : 0x%Xu;\n",
+				t.minus|t.infCode, t.minus|t.maxMagnitude)
+		} else {
+			fmt.Fprintf(b, "        code = 0x%Xu;\n", t.minus|t.maxMagnitude)
+		}
+		fmt.Fprintf(b, "    } else if (v < 0) {\n")
+		fmt.Fprintf(b, "        code = (uint16_t)(0x%Xu | %s_fixed_magnitude((((uint64_t)-(int64_t)v * %du) >> %d) + %du));\n",
+			t.minus, p, c.ratio, t.mSize+1, c.a)
+	}
+	fmt.Fprintf(b, "    } else {\n")
+	fmt.Fprintf(b, "        code = %s_fixed_magnitude((((uint64_t)v * %du) >> %d) + %du);\n    }\n",
+		p, c.ratio, t.mSize+1, c.a)
+
+	writeCZeroAndReserved(b, t, func(v float64) string {
+		return strconv.FormatInt(cFixed(v, h.FractionBits), 10)
+	}, "int64_t")
+	fmt.Fprintf(b, "    return %s_finish(code);\n}\n\n", p)
+
+	// The decoder.
+	fmt.Fprintf(b, "static int32_t %s_decode_fixed(uint16_t code)\n{\n", p)
+	fmt.Fprintf(b, "    uint16_t x = %s_start(code);\n    int64_t v;\n", p)
+	writeCSpecial(b, t, "0", "INT32_MAX", "INT32_MIN", "0")
+	fmt.Fprintf(b, "    v = (int64_t)(%du + %du * (uint32_t)(x & 0x%Xu)) * %s_fixed_scale[(x >> %d) & 0x%Xu]"+
+		" - %d + %d;\n", uint32(1)<<t.mSize, t.xBase-1, t.mMask, p, t.mSize, t.xMask,
+		c.offset, int64(1)<<(c.guard-1))
+	fmt.Fprintf(b, "    v = (v < 0) ? 0 : (v >> %d);\n", c.guard)
+	if 0 != t.minus {
+		fmt.Fprintf(b, "    return (int32_t)((0 != (x & 0x%Xu)) ? -v : v);\n}\n\n", t.minus)
+	} else {
+		fmt.Fprintf(b, "    return (int32_t)v;\n}\n\n")
+	}
+}
+
+func writeCTables(b *bytes.Buffer, t *Type, h *CHeader) {
+	p := h.Prefix
+	size := int(t.bitmask) + 1
+
+	fmt.Fprintf(b, "static const float %s_table[%d] = {\n", p, size)
+	for i := 0; i < size; i++ {
+		fmt.Fprintf(b, "    %s,\n", cFloat(decode(uint16(i)<<t.align, t)))
+	}
+	fmt.Fprintf(b, "};\n\n")
+
+	fmt.Fprintf(b, "static float %s_decode_table(uint16_t code)\n{\n", p)
+	fmt.Fprintf(b, "    return %s_table[(code >> %d) & 0x%Xu];\n}\n\n", p, t.align, t.bitmask)
+
+	if 0 == h.FractionBits {
+		return
+	}
+
+	fmt.Fprintf(b, "static const int32_t %s_fixed_table[%d] = {\n", p, size)
+	for i := 0; i < size; i++ {
+		v := cFixed(decode(uint16(i)<<t.align, t), h.FractionBits)
+		fmt.Fprintf(b, "    %s,\n", cFixedLiteral(v))
+	}
+	fmt.Fprintf(b, "};\n\n")
+
+	fmt.Fprintf(b, "static int32_t %s_decode_fixed_table(uint16_t code)\n{\n", p)
+	fmt.Fprintf(b, "    return %s_fixed_table[(code >> %d) & 0x%Xu];\n}\n\n", p, t.align, t.bitmask)
+}
+
+// cVector is a golden vector of the C test.
+type cVector struct {
+	input, expected string
+	tolerance       string
+}
+
+func writeCTest(b *bytes.Buffer, t *Type, h *CHeader, include string) {
+	p := h.Prefix
+	a := t.data.scale[0]
+	c := 1 / (1 - a)
+	size := int(t.bitmask) + 1
+
+	// The codes and their values in the comparable order.
+	codes := make([]uint16, 0, size)
+	values := make([]float64, 0, size)
+	for i := 0; i < size; i++ {
+		code := t.Protect(toLayout(fromComparable(uint16(i), t)&t.bitmask, t))
+		codes = append(codes, code)
+		values = append(values, t.Decode(code))
+	}
+
+	// The centers of the rounding bins, and the points between
+	// the centers and the boundaries.
+	var inputs []float64
+	for i, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		inputs = append(inputs, v)
+		for j := i + 1; j < len(values); j++ {
+			if next := values[j]; !math.IsNaN(next) && (next > v) {
+				if !math.IsInf(next, 0) {
+					inputs = append(inputs, v+(next-v)/4, next-(next-v)/4)
+				}
+				break
+			}
+		}
+	}
+
+	special := []float64{math.NaN(), math.Inf(+1), math.Inf(-1),
+		math.Copysign(0, -1), t.maxValue * 2, t.minValue * 2, -1e-30, 1e-30}
+
+	var decodeFloat, encodeFloat []cVector
+	for i, code := range codes {
+		v := values[i]
+		tolerance := 0.0
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			tolerance = 1e-6 * (math.Abs(v) + a*c)
+		}
+		decodeFloat = append(decodeFloat, cVector{
+			fmt.Sprintf("0x%Xu", code), cFloat(v), cFloat(tolerance)})
+	}
+	for _, v := range append(inputs, special...) {
+		v32 := float64(float32(v))
+		encodeFloat = append(encodeFloat, cVector{
+			cFloat(v32), fmt.Sprintf("0x%Xu", t.Encode(v32)), ""})
+	}
+
+	fmt.Fprintf(b, "/* Code generated by toyfloat.WriteCTest; DO NOT EDIT. */\n")
+	fmt.Fprintf(b, "/* The type %s. */\n\n", t.Spec())
+	fmt.Fprintf(b, "#include <math.h>\n#include <stdint.h>\n#include <stdio.h>\n\n")
+	fmt.Fprintf(b, "#include %q\n\n", include)
+
+	writeCVectors(b, "uint16_t", "float", "decode_float", decodeFloat)
+	writeCVectors(b, "float", "uint16_t", "encode_float", encodeFloat)
+
+	var decodeFixed, encodeFixed []cVector
+	if 0 != h.FractionBits {
+		unit := math.Ldexp(1, -int(h.FractionBits))
+		fixed := func(q int64) float64 {
+			switch q {
+			case math.MaxInt32:
+				return math.Inf(+1)
+			case math.MinInt32:
+				return math.Inf(-1)
+			}
+			return float64(q) * unit
+		}
+
+		for i, code := range codes {
+			v := values[i]
+			tolerance := "1"
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				tolerance = "0"
+			}
+			decodeFixed = append(decodeFixed, cVector{
+				fmt.Sprintf("0x%Xu", code), cFixedLiteral(cFixed(v, h.FractionBits)), tolerance})
+		}
+
+		// The rounding of the fixed-point variants may differ
+		// next to the boundaries, and the neighbours tell that.
+		specialFixed := []int64{math.MaxInt32, math.MinInt32, 0, 1, -1}
+		for _, v := range append(inputs, t.maxValue, t.minValue, t.maxValue*2, t.minValue*2) {
+			q := cFixed(v, h.FractionBits)
+			if q >= math.MaxInt32 || q <= math.MinInt32 {
+				continue
+			}
+			expected := t.Encode(fixed(q))
+			if (t.Encode(fixed(q-1)) == expected) && (t.Encode(fixed(q+1)) == expected) {
+				specialFixed = append(specialFixed, q)
+			}
+		}
+		for _, q := range specialFixed {
+			encodeFixed = append(encodeFixed, cVector{
+				cFixedLiteral(q), fmt.Sprintf("0x%Xu", t.Encode(fixed(q))), ""})
+		}
+
+		writeCVectors(b, "uint16_t", "int32_t", "decode_fixed", decodeFixed)
+		writeCVectors(b, "int32_t", "uint16_t", "encode_fixed", encodeFixed)
+	}
+
+	fmt.Fprintf(b, "int main(void)\n{\n    int failures = 0;\n    int i;\n\n")
+
+	fmt.Fprintf(b, "    for (i = 0; i < %d; i++) {\n", len(decodeFloat))
+	fmt.Fprintf(b, "        float v = %s_decode(decode_float_input[i]);\n", p)
+	fmt.Fprintf(b, "        float e = decode_float_expected[i];\n")
+	fmt.Fprintf(b, "        if ((e != e) ? (v == v) : !(fabsf(v - e) <= decode_float_tolerance[i]"+
+		" || v == e)) {\n")
+	fmt.Fprintf(b, "            printf(\"%s_decode(0x%%X) = %%.9g, expected %%.9g\\n\",\n"+
+		"                (unsigned)decode_float_input[i], (double)v, (double)e);\n", p)
+	fmt.Fprintf(b, "            failures++;\n        }\n")
+	if h.Tables {
+		fmt.Fprintf(b, "        v = %s_decode_table(decode_float_input[i]);\n", p)
+		fmt.Fprintf(b, "        if ((e != e) ? (v == v) : (v != e)) {\n")
+		fmt.Fprintf(b, "            printf(\"%s_decode_table(0x%%X) = %%.9g, expected %%.9g\\n\",\n"+
+			"                (unsigned)decode_float_input[i], (double)v, (double)e);\n", p)
+		fmt.Fprintf(b, "            failures++;\n        }\n")
+	}
+	fmt.Fprintf(b, "    }\n\n")
+
+	fmt.Fprintf(b, "    for (i = 0; i < %d; i++) {\n", len(encodeFloat))
+	fmt.Fprintf(b, "        uint16_t code = %s_encode(encode_float_input[i]);\n", p)
+	fmt.Fprintf(b, "        if (code != encode_float_expected[i]) {\n")
+	fmt.Fprintf(b, "            printf(\"%s_encode(%%.9g) = 0x%%X, expected 0x%%X\\n\",\n"+
+		"                (double)encode_float_input[i], (unsigned)code,"+
+		" (unsigned)encode_float_expected[i]);\n", p)
+	fmt.Fprintf(b, "            failures++;\n        }\n    }\n\n")
+
+	if 0 != h.FractionBits {
+		fmt.Fprintf(b, "    for (i = 0; i < %d; i++) {\n", len(decodeFixed))
+		fmt.Fprintf(b, "        int32_t v = %s_decode_fixed(decode_fixed_input[i]);\n", p)
+		fmt.Fprintf(b, "        int64_t d = (int64_t)v - decode_fixed_expected[i];\n")
+		fmt.Fprintf(b, "        if (d < -decode_fixed_tolerance[i] || d > decode_fixed_tolerance[i]) {\n")
+		fmt.Fprintf(b, "            printf(\"%s_decode_fixed(0x%%X) = %%ld, expected %%ld\\n\",\n"+
+			"                (unsigned)decode_fixed_input[i], (long)v, (long)decode_fixed_expected[i]);\n", p)
+		fmt.Fprintf(b, "            failures++;\n        }\n")
+		if h.Tables {
+			fmt.Fprintf(b, "        v = %s_decode_fixed_table(decode_fixed_input[i]);\n", p)
+			fmt.Fprintf(b, "        if (v != decode_fixed_expected[i]) {\n")
+			fmt.Fprintf(b, "            printf(\"%s_decode_fixed_table(0x%%X) = %%ld, expected %%ld\\n\",\n"+
+				"                (unsigned)decode_fixed_input[i], (long)v, (long)decode_fixed_expected[i]);\n", p)
+			fmt.Fprintf(b, "            failures++;\n        }\n")
+		}
+		fmt.Fprintf(b, "    }\n\n")
+
+		fmt.Fprintf(b, "    for (i = 0; i < %d; i++) {\n", len(encodeFixed))
+		fmt.Fprintf(b, "        uint16_t code = %s_encode_fixed(encode_fixed_input[i]);\n", p)
+		fmt.Fprintf(b, "        if (code != encode_fixed_expected[i]) {\n")
+		fmt.Fprintf(b, "            printf(\"%s_encode_fixed(%%ld) = 0x%%X, expected 0x%%X\\n\",\n"+
+			"                (long)encode_fixed_input[i], (unsigned)code,"+
+			" (unsigned)encode_fixed_expected[i]);\n", p)
+		fmt.Fprintf(b, "            failures++;\n        }\n    }\n\n")
+	}
+
+	fmt.Fprintf(b, "    if (0 != failures) {\n        printf(\"%%d failures\\n\", failures);\n"+
+		"        return 1;\n    }\n    return 0;\n}\n")
+}
+
+// writeCVectors writes the arrays name_input, name_expected,
+// and name_tolerance, if the vectors have tolerances.
+func writeCVectors(b *bytes.Buffer, inputType, expectedType, name string, vectors []cVector) {
+	write := func(cType, suffix string, value func(v cVector) string) {
+		fmt.Fprintf(b, "static const %s %s_%s[%d] = {\n", cType, name, suffix, len(vectors))
+		for _, v := range vectors {
+			fmt.Fprintf(b, "    %s,\n", value(v))
+		}
+		fmt.Fprintf(b, "};\n\n")
+	}
+
+	write(inputType, "input", func(v cVector) string { return v.input })
+	write(expectedType, "expected", func(v cVector) string { return v.expected })
+	if len(vectors) > 0 && "" != vectors[0].tolerance {
+		tolerance := expectedType
+		if "int32_t" == tolerance {
+			tolerance = "int64_t"
+		}
+		write(tolerance, "tolerance", func(v cVector) string { return v.tolerance })
+	}
+}
+
+// cFixedLiteral prints INT32_MIN as a macro,
+// since -2147483648 is not an int literal.
+func cFixedLiteral(v int64) string {
+	if math.MinInt32 == v {
+		return "INT32_MIN"
+	}
+	return strconv.FormatInt(v, 10)
+}
@@ -0,0 +1,88 @@
+package toyfloat
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteC(t *testing.T) {
+	cc, err := exec.LookPath("cc")
+	if err != nil {
+		t.Skip("no C compiler")
+	}
+
+	headers := []struct {
+		spec string
+		h    CHeader
+	}{
+		{"s12x4b2m-8", CHeader{"x12", 16, true}},
+		{"u5x3b2m-6", CHeader{"u5", 12, true}},
+		{"15x3", CHeader{"x15", 16, false}},
+		{"s16x2b3m-3+nonfinite+zero=canonical", CHeader{"x16", 0, false}},
+		{"s10x3b2m-6+nonfinite+zero=marker+twos+layout=sxm.msb+protect=crc4",
+			CHeader{"twos", 20, true}},
+		{"s9x2b3m-3+reserved=1.2+layout=xms+protect=hamming", CHeader{"r9", 16, true}},
+		{"s11x3b2m-6+twos", CHeader{"keep", 8, false}},
+	}
+
+	dir, err := ioutil.TempDir("", "toyfloat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, x := range headers {
+		tf, err := ParseTag(x.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var header, program bytes.Buffer
+		if err := WriteC(&header, tf, x.h); err != nil {
+			t.Fatalf("%s: %v", x.spec, err)
+		} else if err := WriteCTest(&program, tf, x.h, x.h.Prefix+".h"); err != nil {
+			t.Fatalf("%s: %v", x.spec, err)
+		}
+
+		source := filepath.Join(dir, x.h.Prefix+".c")
+		binary := filepath.Join(dir, x.h.Prefix)
+		if err := ioutil.WriteFile(filepath.Join(dir, x.h.Prefix+".h"), header.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		} else if err := ioutil.WriteFile(source, program.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := exec.Command(cc, "-std=c99", "-Wall", "-Wextra", "-Werror", "-pedantic",
+			"-o", binary, source, "-lm").CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", x.spec, err, out)
+		}
+
+		if out, err := exec.Command(binary).CombinedOutput(); err != nil {
+			t.Fatalf("%s: %v\n%s", x.spec, err, out)
+		}
+	}
+}
+
+func TestWriteCErrors(t *testing.T) {
+	tf, err := ParseType("s12x4b2m-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, h := range []CHeader{{"", 0, false}, {"1x", 0, false}, {"x-y", 0, false},
+		{"x", 25, false}, {"x", 24, false}} {
+
+		if err := WriteC(&bytes.Buffer{}, tf, h); err != ErrInvalidCHeader {
+			t.Fatalf("%+v: ErrInvalidCHeader expected, got %v", h, err)
+		}
+	}
+
+	if err := WriteC(&bytes.Buffer{}, Type{}, CHeader{Prefix: "x"}); err != ErrInvalidType {
+		t.Fatalf("ErrInvalidType expected, got %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+// Package example is packed by the code that toyfloatgen generates.
+// Its tests compare the generated code with toyfloat.Marshal.
+package example
+
+//go:generate go run github.com/georgy7/toyfloat/cmd/toyfloatgen -type Point,Track
+
+// Meters shows that named types are converted.
+type Meters float32
+
+type Point struct {
+	X, Y  float64 `toyfloat:"x4,12,signed"`
+	Z     Meters  `toyfloat:"15x3"`
+	Alpha float64 `toyfloat:"s10x3b2m-6+nonfinite+zero=marker+twos+layout=sxm.msb+protect=crc4"`
+	Beta  float64 `toyfloat:"s9x2b3m-3+reserved=1.2+layout=xms"`
+	Flag  bool
+	Count uint8  `toyfloat:"5"`
+	Shift int16  `toyfloat:"7"`
+	Note  string `toyfloat:"-"`
+	extra int
+}
+
+type Track struct {
+	ID     uint16
+	Points [3]Point
+	Gains  [2][2]float64 `toyfloat:"x3,5"`
+	Head   struct {
+		Big   int64
+		Small int `toyfloat:"3"`
+	}
+}
@@ -0,0 +1,161 @@
+package example
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/georgy7/toyfloat"
+)
+
+var intResult int
+
+func randomFloat(r *rand.Rand) float64 {
+	switch r.Intn(16) {
+	case 0:
+		return math.Inf(+1)
+	case 1:
+		return math.Inf(-1)
+	case 2:
+		return math.NaN()
+	case 3:
+		return 0
+	case 4:
+		return math.Copysign(0, -1)
+	}
+	return (r.Float64() - 0.5) * math.Pow(2, float64(r.Intn(24)-12))
+}
+
+func randomPoint(r *rand.Rand) Point {
+	return Point{
+		X:     randomFloat(r),
+		Y:     randomFloat(r),
+		Z:     Meters(randomFloat(r)),
+		Alpha: randomFloat(r),
+		Beta:  randomFloat(r),
+		Flag:  r.Intn(2) == 0,
+		Count: uint8(r.Intn(32)),
+		Shift: int16(r.Intn(128) - 64),
+	}
+}
+
+func TestSameAsMarshal(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 20000; i++ {
+		var track Track
+		track.ID = uint16(r.Intn(1 << 16))
+		for j := range track.Points {
+			track.Points[j] = randomPoint(r)
+		}
+		for j := range track.Gains {
+			track.Gains[j] = [2]float64{randomFloat(r), randomFloat(r)}
+		}
+		track.Head.Big = r.Int63() - r.Int63()
+		track.Head.Small = r.Intn(8) - 4
+
+		expected, err := toyfloat.Marshal(&track)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := track.MarshalToyfloat()
+		if err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(data, expected) {
+			t.Fatalf("%+v:\n%X !=\n%X", track, data, expected)
+		}
+
+		var back, backReflection Track
+		if err := back.UnmarshalToyfloat(data); err != nil {
+			t.Fatal(err)
+		} else if err := toyfloat.Unmarshal(data, &backReflection); err != nil {
+			t.Fatal(err)
+		}
+
+		// NaN is not equal to itself, and -0 is equal to +0,
+		// so the printed structs are compared.
+		if fmt.Sprintf("%+v", back) != fmt.Sprintf("%+v", backReflection) {
+			t.Fatalf("%+v != %+v", back, backReflection)
+		}
+	}
+}
+
+func TestSameErrors(t *testing.T) {
+	points := []Point{{Count: 32}, {Shift: 64}, {Shift: -65}}
+	for _, p := range points {
+		_, expected := toyfloat.Marshal(&p)
+		_, err := p.MarshalToyfloat()
+
+		if !errors.Is(err, toyfloat.ErrValueOutOfRange) {
+			t.Fatalf("ErrValueOutOfRange expected, got %v", err)
+		} else if err.Error() != expected.Error() {
+			t.Fatalf("%v != %v", err, expected)
+		}
+	}
+
+	var track Track
+	track.Head.Small = 4
+	_, expected := toyfloat.Marshal(&track)
+	if _, err := track.MarshalToyfloat(); !reflect.DeepEqual(err, expected) {
+		t.Fatalf("%v != %v", err, expected)
+	}
+
+	var p Point
+	expected = toyfloat.Unmarshal(make([]byte, 3), &p)
+	if err := p.UnmarshalToyfloat(make([]byte, 3)); err.Error() != expected.Error() {
+		t.Fatalf("%v != %v", err, expected)
+	} else if !errors.Is(err, toyfloat.ErrRecordLength) {
+		t.Fatalf("ErrRecordLength expected, got %v", err)
+	}
+}
+
+func BenchmarkMarshalToyfloat(b *testing.B) {
+	p := randomPoint(rand.New(rand.NewSource(1)))
+
+	r := 0
+	for i := 0; i < b.N; i++ {
+		data, _ := p.MarshalToyfloat()
+		r += len(data)
+	}
+	intResult = r
+}
+
+func BenchmarkMarshalReflection(b *testing.B) {
+	p := randomPoint(rand.New(rand.NewSource(1)))
+
+	r := 0
+	for i := 0; i < b.N; i++ {
+		data, _ := toyfloat.Marshal(&p)
+		r += len(data)
+	}
+	intResult = r
+}
+
+func BenchmarkUnmarshalToyfloat(b *testing.B) {
+	p := randomPoint(rand.New(rand.NewSource(1)))
+	data, _ := p.MarshalToyfloat()
+
+	r := 0
+	for i := 0; i < b.N; i++ {
+		_ = p.UnmarshalToyfloat(data)
+		r += int(p.Count)
+	}
+	intResult = r
+}
+
+func BenchmarkUnmarshalReflection(b *testing.B) {
+	p := randomPoint(rand.New(rand.NewSource(1)))
+	data, _ := p.MarshalToyfloat()
+
+	r := 0
+	for i := 0; i < b.N; i++ {
+		_ = toyfloat.Unmarshal(data, &p)
+		r += int(p.Count)
+	}
+	intResult = r
+}
@@ -0,0 +1,637 @@
+// Code generated by toyfloatgen -type Point,Track; DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/georgy7/toyfloat"
+)
+
+// MarshalToyfloat packs the struct the way toyfloat.Marshal does.
+func (v *Point) MarshalToyfloat() ([]byte, error) {
+	data := make([]byte, 9)
+	o := uint(0)
+
+	o = toyfloatPointPut(data, o, uint64(toyfloatPointEncode0(v.X)&0xFFF), 12)
+	o = toyfloatPointPut(data, o, uint64(toyfloatPointEncode0(v.Y)&0xFFF), 12)
+	o = toyfloatPointPut(data, o, uint64(toyfloatPointEncode1(float64(v.Z))&0x7FFF), 15)
+	o = toyfloatPointPut(data, o, uint64((toyfloatPointEncode2(v.Alpha)>>6)&0x3FF), 10)
+	o = toyfloatPointPut(data, o, uint64(toyfloatPointEncode3(v.Beta)&0x1FF), 9)
+	o = toyfloatPointPut(data, o, toyfloatPointBit(v.Flag), 1)
+	if x := uint64(v.Count); 0 != x>>5 {
+		return nil, fmt.Errorf("field Point.Count = %d: %w", x, toyfloat.ErrValueOutOfRange)
+	}
+	o = toyfloatPointPut(data, o, uint64(v.Count), 5)
+	if x := int64(v.Shift); (x < -64) || (x >= 64) {
+		return nil, fmt.Errorf("field Point.Shift = %d: %w", x, toyfloat.ErrValueOutOfRange)
+	}
+	o = toyfloatPointPut(data, o, uint64(v.Shift), 7)
+	return data, nil
+}
+
+// UnmarshalToyfloat is MarshalToyfloat in reverse, the same as toyfloat.Unmarshal.
+func (v *Point) UnmarshalToyfloat(data []byte) error {
+	if len(data) != 9 {
+		return fmt.Errorf("%d bytes for example.Point: %w", len(data), toyfloat.ErrRecordLength)
+	}
+
+	var g uint64
+	o := uint(0)
+
+	g, o = toyfloatPointGet(data, o, 12)
+	v.X = toyfloatPointDecode0(uint16(g))
+	g, o = toyfloatPointGet(data, o, 12)
+	v.Y = toyfloatPointDecode0(uint16(g))
+	g, o = toyfloatPointGet(data, o, 15)
+	v.Z = Meters(toyfloatPointDecode1(uint16(g)))
+	g, o = toyfloatPointGet(data, o, 10)
+	v.Alpha = toyfloatPointDecode2(uint16(g) << 6)
+	g, o = toyfloatPointGet(data, o, 9)
+	v.Beta = toyfloatPointDecode3(uint16(g))
+	g, o = toyfloatPointGet(data, o, 1)
+	v.Flag = 0 != g
+	g, o = toyfloatPointGet(data, o, 5)
+	v.Count = uint8(g)
+	g, o = toyfloatPointGet(data, o, 7)
+	v.Shift = int16(int64(g<<57) >> 57)
+	return nil
+}
+
+// MarshalToyfloat packs the struct the way toyfloat.Marshal does.
+func (v *Track) MarshalToyfloat() ([]byte, error) {
+	data := make([]byte, 40)
+	o := uint(0)
+
+	o = toyfloatPointPut(data, o, uint64(v.ID), 16)
+	for i0 := range v.Points {
+		o = toyfloatPointPut(data, o, uint64(toyfloatPointEncode0(v.Points[i0].X)&0xFFF), 12)
+		o = toyfloatPointPut(data, o, uint64(toyfloatPointEncode0(v.Points[i0].Y)&0xFFF), 12)
+		o = toyfloatPointPut(data, o, uint64(toyfloatPointEncode1(float64(v.Points[i0].Z))&0x7FFF), 15)
+		o = toyfloatPointPut(data, o, uint64((toyfloatPointEncode2(v.Points[i0].Alpha)>>6)&0x3FF), 10)
+		o = toyfloatPointPut(data, o, uint64(toyfloatPointEncode3(v.Points[i0].Beta)&0x1FF), 9)
+		o = toyfloatPointPut(data, o, toyfloatPointBit(v.Points[i0].Flag), 1)
+		if x := uint64(v.Points[i0].Count); 0 != x>>5 {
+			return nil, fmt.Errorf("field Track.Points[].Count = %d: %w", x, toyfloat.ErrValueOutOfRange)
+		}
+		o = toyfloatPointPut(data, o, uint64(v.Points[i0].Count), 5)
+		if x := int64(v.Points[i0].Shift); (x < -64) || (x >= 64) {
+			return nil, fmt.Errorf("field Track.Points[].Shift = %d: %w", x, toyfloat.ErrValueOutOfRange)
+		}
+		o = toyfloatPointPut(data, o, uint64(v.Points[i0].Shift), 7)
+	}
+	for i0 := range v.Gains {
+		for i1 := range v.Gains[i0] {
+			o = toyfloatPointPut(data, o, uint64(toyfloatPointEncode4(v.Gains[i0][i1])&0x1F), 5)
+		}
+	}
+	o = toyfloatPointPut(data, o, uint64(v.Head.Big), 64)
+	if x := int64(v.Head.Small); (x < -4) || (x >= 4) {
+		return nil, fmt.Errorf("field Track.Head.Small = %d: %w", x, toyfloat.ErrValueOutOfRange)
+	}
+	o = toyfloatPointPut(data, o, uint64(v.Head.Small), 3)
+	return data, nil
+}
+
+// UnmarshalToyfloat is MarshalToyfloat in reverse, the same as toyfloat.Unmarshal.
+func (v *Track) UnmarshalToyfloat(data []byte) error {
+	if len(data) != 40 {
+		return fmt.Errorf("%d bytes for example.Track: %w", len(data), toyfloat.ErrRecordLength)
+	}
+
+	var g uint64
+	o := uint(0)
+
+	g, o = toyfloatPointGet(data, o, 16)
+	v.ID = uint16(g)
+	for i0 := range v.Points {
+		g, o = toyfloatPointGet(data, o, 12)
+		v.Points[i0].X = toyfloatPointDecode0(uint16(g))
+		g, o = toyfloatPointGet(data, o, 12)
+		v.Points[i0].Y = toyfloatPointDecode0(uint16(g))
+		g, o = toyfloatPointGet(data, o, 15)
+		v.Points[i0].Z = Meters(toyfloatPointDecode1(uint16(g)))
+		g, o = toyfloatPointGet(data, o, 10)
+		v.Points[i0].Alpha = toyfloatPointDecode2(uint16(g) << 6)
+		g, o = toyfloatPointGet(data, o, 9)
+		v.Points[i0].Beta = toyfloatPointDecode3(uint16(g))
+		g, o = toyfloatPointGet(data, o, 1)
+		v.Points[i0].Flag = 0 != g
+		g, o = toyfloatPointGet(data, o, 5)
+		v.Points[i0].Count = uint8(g)
+		g, o = toyfloatPointGet(data, o, 7)
+		v.Points[i0].Shift = int16(int64(g<<57) >> 57)
+	}
+	for i0 := range v.Gains {
+		for i1 := range v.Gains[i0] {
+			g, o = toyfloatPointGet(data, o, 5)
+			v.Gains[i0][i1] = toyfloatPointDecode4(uint16(g))
+		}
+	}
+	g, o = toyfloatPointGet(data, o, 64)
+	v.Head.Big = int64(int64(g))
+	g, o = toyfloatPointGet(data, o, 3)
+	v.Head.Small = int(int64(g<<61) >> 61)
+	return nil
+}
+
+// toyfloatPointPut writes n bits of x at the offset, from the most-significant one.
+func toyfloatPointPut(data []byte, offset uint, x uint64, n uint) uint {
+	for n > 0 {
+		free := 8 - offset%8
+		take := n
+		if take > free {
+			take = free
+		}
+
+		chunk := byte(x>>(n-take)) & (byte(1)<<take - 1)
+		data[offset/8] |= chunk << (free - take)
+		offset += take
+		n -= take
+	}
+	return offset
+}
+
+// toyfloatPointGet is toyfloatPointPut in reverse.
+func toyfloatPointGet(data []byte, offset uint, n uint) (uint64, uint) {
+	x := uint64(0)
+	for n > 0 {
+		free := 8 - offset%8
+		take := n
+		if take > free {
+			take = free
+		}
+
+		chunk := (data[offset/8] >> (free - take)) & (byte(1)<<take - 1)
+		x = x<<take | uint64(chunk)
+		offset += take
+		n -= take
+	}
+	return x, offset
+}
+
+func toyfloatPointBit(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// toyfloatPointEncode0 is Encode of the type s12x4b2m-8.
+func toyfloatPointEncode0(v float64) uint16 {
+	var code uint16
+	switch {
+	case math.IsNaN(v):
+		code = 0x0
+	case v > 255.99607843137255:
+		code = 0x7FF
+	case v < -255.99607843137255:
+		code = 0xFFF
+	case v < 0:
+		code = 0x800 | toyfloatPointEncode0Magnitude(0.00390625-v*0.99609375)
+	default:
+		code = toyfloatPointEncode0Magnitude(0.00390625 + v*0.99609375)
+	}
+
+	return code
+}
+
+func toyfloatPointEncode0Magnitude(inner float64) uint16 {
+	if inner >= 0.998046875 {
+		if inner >= 15.96875 {
+			if inner >= 63.875 {
+				if inner >= 127.75 {
+					return uint16(128.0*inner*0.0078125-128.0+0.499999999999) | 0x780
+				}
+				return uint16(128.0*inner*0.015625-128.0+0.499999999999) | 0x700
+			}
+			if inner >= 31.9375 {
+				return uint16(128.0*inner*0.03125-128.0+0.499999999999) | 0x680
+			}
+			return uint16(128.0*inner*0.0625-128.0+0.499999999999) | 0x600
+		}
+		if inner >= 3.9921875 {
+			if inner >= 7.984375 {
+				return uint16(128.0*inner*0.125-128.0+0.499999999999) | 0x580
+			}
+			return uint16(128.0*inner*0.25-128.0+0.499999999999) | 0x500
+		}
+		if inner >= 1.99609375 {
+			return uint16(128.0*inner*0.5-128.0+0.499999999999) | 0x480
+		}
+		return uint16(128.0*inner*1.0-128.0+0.499999999999) | 0x400
+	}
+	if inner >= 0.0623779296875 {
+		if inner >= 0.24951171875 {
+			if inner >= 0.4990234375 {
+				return uint16(128.0*inner*2.0-128.0+0.499999999999) | 0x380
+			}
+			return uint16(128.0*inner*4.0-128.0+0.499999999999) | 0x300
+		}
+		if inner >= 0.124755859375 {
+			return uint16(128.0*inner*8.0-128.0+0.499999999999) | 0x280
+		}
+		return uint16(128.0*inner*16.0-128.0+0.499999999999) | 0x200
+	}
+	if inner >= 0.015594482421875 {
+		if inner >= 0.03118896484375 {
+			return uint16(128.0*inner*32.0-128.0+0.499999999999) | 0x180
+		}
+		return uint16(128.0*inner*64.0-128.0+0.499999999999) | 0x100
+	}
+	if inner >= 0.0077972412109375 {
+		return uint16(128.0*inner*128.0-128.0+0.499999999999) | 0x80
+	}
+	return uint16(128.0*inner*256.0-128.0+0.499999999999) | 0x0
+}
+
+// toyfloatPointDecode0 is Decode of the type s12x4b2m-8.
+func toyfloatPointDecode0(code uint16) float64 {
+	x := code & 0xFFF
+
+	v := ((1.0+float64(x&0x7F)*0.0078125)*toyfloatPointDecode0Scale[(x>>7)&0xF] - 0.00390625) * 1.003921568627451
+	if ((1.0 - 1e-14) < v) && (v < (1.0 + 1e-14)) {
+		v = 1.0
+	}
+	if 0 != x&0x800 {
+		return -v
+	}
+	return v
+}
+
+var toyfloatPointDecode0Scale = [16]float64{
+	0.00390625,
+	0.0078125,
+	0.015625,
+	0.03125,
+	0.0625,
+	0.125,
+	0.25,
+	0.5,
+	1.0,
+	2.0,
+	4.0,
+	8.0,
+	16.0,
+	32.0,
+	64.0,
+	128.0,
+}
+
+// toyfloatPointEncode1 is Encode of the type s15x3b2m-6.
+func toyfloatPointEncode1(v float64) uint16 {
+	var code uint16
+	switch {
+	case math.IsNaN(v):
+		code = 0x0
+	case v > 4.046626984126984:
+		code = 0x3FFF
+	case v < -4.046626984126984:
+		code = 0x7FFF
+	case v < 0:
+		code = 0x4000 | toyfloatPointEncode1Magnitude(0.015625-v*0.984375)
+	default:
+		code = toyfloatPointEncode1Magnitude(0.015625 + v*0.984375)
+	}
+
+	return code
+}
+
+func toyfloatPointEncode1Magnitude(inner float64) uint16 {
+	if inner >= 0.249969482421875 {
+		if inner >= 0.9998779296875 {
+			if inner >= 1.999755859375 {
+				return uint16(2048.0*inner*0.5-2048.0+0.499999999999) | 0x3800
+			}
+			return uint16(2048.0*inner*1.0-2048.0+0.499999999999) | 0x3000
+		}
+		if inner >= 0.49993896484375 {
+			return uint16(2048.0*inner*2.0-2048.0+0.499999999999) | 0x2800
+		}
+		return uint16(2048.0*inner*4.0-2048.0+0.499999999999) | 0x2000
+	}
+	if inner >= 0.06249237060546875 {
+		if inner >= 0.1249847412109375 {
+			return uint16(2048.0*inner*8.0-2048.0+0.499999999999) | 0x1800
+		}
+		return uint16(2048.0*inner*16.0-2048.0+0.499999999999) | 0x1000
+	}
+	if inner >= 0.031246185302734375 {
+		return uint16(2048.0*inner*32.0-2048.0+0.499999999999) | 0x800
+	}
+	return uint16(2048.0*inner*64.0-2048.0+0.499999999999) | 0x0
+}
+
+// toyfloatPointDecode1 is Decode of the type s15x3b2m-6.
+func toyfloatPointDecode1(code uint16) float64 {
+	x := code & 0x7FFF
+
+	v := ((1.0+float64(x&0x7FF)*0.00048828125)*toyfloatPointDecode1Scale[(x>>11)&0x7] - 0.015625) * 1.0158730158730158
+	if ((1.0 - 1e-14) < v) && (v < (1.0 + 1e-14)) {
+		v = 1.0
+	}
+	if 0 != x&0x4000 {
+		return -v
+	}
+	return v
+}
+
+var toyfloatPointDecode1Scale = [8]float64{
+	0.015625,
+	0.03125,
+	0.0625,
+	0.125,
+	0.25,
+	0.5,
+	1.0,
+	2.0,
+}
+
+// toyfloatPointEncode2 is Encode of the type s10x3b2m-6+nonfinite+zero=marker+twos+layout=sxm.msb+protect=crc4.
+func toyfloatPointEncode2(v float64) uint16 {
+	var code uint16
+	switch {
+	case math.IsNaN(v):
+		code = 0x1FE
+	case v > 3.952380952380952:
+		code = 0x1FD
+		if math.IsInf(v, +1) {
+			code = 0x1FF
+		}
+	case v < -3.952380952380952:
+		code = 0x3FD
+		if math.IsInf(v, -1) {
+			code = 0x3FF
+		}
+	case v < 0:
+		code = 0x200 | toyfloatPointEncode2Magnitude(0.015625-v*0.984375)
+	default:
+		code = toyfloatPointEncode2Magnitude(0.015625 + v*0.984375)
+	}
+
+	if code == 0x200 {
+		code = 0x0
+	}
+
+	// Two's complement.
+	if 0 == code&0x200 {
+		code |= 0x200
+	} else {
+		if code == 0x200 {
+			code = 0x0
+		} else {
+			code = ^code + 1
+		}
+	}
+	code = (code & 0x3FF) ^ 0x200
+	if 0 != code&0x200 {
+		code |= 0xFC00
+	}
+
+	// The layout sxm.msb.
+	x := code & 0x3FF
+	code = (x>>6&0x7)<<6 | (x&0x3F)<<0
+	if 0 != x&0x200 {
+		code |= 0x200
+	}
+	code <<= 6
+
+	// The check bits of crc4.
+	value := (code >> 6) & 0x3FF
+	check := uint16(0)
+	for i, column := range toyfloatPointEncode2Check {
+		if 0 != (value>>uint(i))&1 {
+			check ^= column
+		}
+	}
+	return code&0xFFC0 | (check<<0)&0x3F
+}
+
+func toyfloatPointEncode2Magnitude(inner float64) uint16 {
+	if inner >= 0.2490234375 {
+		if inner >= 0.99609375 {
+			if inner >= 1.9921875 {
+				return uint16(64.0*inner*0.5-64.0+0.499999999999) | 0x1C0
+			}
+			return uint16(64.0*inner*1.0-64.0+0.499999999999) | 0x180
+		}
+		if inner >= 0.498046875 {
+			return uint16(64.0*inner*2.0-64.0+0.499999999999) | 0x140
+		}
+		return uint16(64.0*inner*4.0-64.0+0.499999999999) | 0x100
+	}
+	if inner >= 0.062255859375 {
+		if inner >= 0.12451171875 {
+			return uint16(64.0*inner*8.0-64.0+0.499999999999) | 0xC0
+		}
+		return uint16(64.0*inner*16.0-64.0+0.499999999999) | 0x80
+	}
+	if inner >= 0.0311279296875 {
+		return uint16(64.0*inner*32.0-64.0+0.499999999999) | 0x40
+	}
+	return uint16(64.0*inner*64.0-64.0+0.499999999999) | 0x0
+}
+
+var toyfloatPointEncode2Check = [10]uint16{0xB, 0xF, 0x7, 0xE, 0x5, 0xA, 0xD, 0x3, 0x6, 0xC}
+
+// toyfloatPointDecode2 is Decode of the type s10x3b2m-6+nonfinite+zero=marker+twos+layout=sxm.msb+protect=crc4.
+func toyfloatPointDecode2(code uint16) float64 {
+	// The layout sxm.msb.
+	p := (code >> 6) & 0x3FF
+	x := (p>>6&0x7)<<6 | p>>0&0x3F | (p>>9&1)<<9
+
+	// Two's complement.
+	c := (x ^ 0x200) & 0x3FF
+	if 0 == c&0x200 {
+		if 0 == c {
+			x = 0x200
+		} else {
+			x = ^(c - 1)
+		}
+	} else {
+		x = c &^ 0x200
+	}
+	x &= 0x3FF
+
+	if x == 0x200 {
+		return math.NaN()
+	}
+
+	switch x & 0x1FF {
+	case 0x1FF:
+		if 0 != x&0x200 {
+			return math.Inf(-1)
+		}
+		return math.Inf(+1)
+	case 0x1FE:
+		return math.NaN()
+	}
+
+	v := ((1.0+float64(x&0x3F)*0.015625)*toyfloatPointDecode2Scale[(x>>6)&0x7] - 0.015625) * 1.0158730158730158
+	if ((1.0 - 1e-14) < v) && (v < (1.0 + 1e-14)) {
+		v = 1.0
+	}
+	if 0 != x&0x200 {
+		return -v
+	}
+	return v
+}
+
+var toyfloatPointDecode2Scale = [8]float64{
+	0.015625,
+	0.03125,
+	0.0625,
+	0.125,
+	0.25,
+	0.5,
+	1.0,
+	2.0,
+}
+
+// toyfloatPointEncode3 is Encode of the type s9x2b3m-3+layout=xms+reserved=1.2.
+func toyfloatPointEncode3(v float64) uint16 {
+	var code uint16
+	switch {
+	case math.IsNaN(v):
+		code = 0x0
+	case v > 3.0444711538461533:
+		code = 0xFF
+	case v < -3.0444711538461533:
+		code = 0x1FF
+	case v < 0:
+		code = 0x100 | toyfloatPointEncode3Magnitude(0.037037037037037035-v*0.962962962962963)
+	default:
+		code = toyfloatPointEncode3Magnitude(0.037037037037037035 + v*0.962962962962963)
+	}
+
+	// Reserved codes.
+	switch code {
+	case 0x1:
+		if math.Abs(0.0-v) < math.Abs(0.0024038461538461496-v) {
+			code = 0x0
+		} else {
+			code = 0x2
+		}
+	case 0x100:
+		if math.Abs(-0.0012019230769230748-v) < math.Abs(0.0-v) {
+			code = 0x101
+		} else {
+			code = 0x0
+		}
+	}
+
+	// The layout xms.
+	x := code & 0x1FF
+	code = (x>>6&0x3)<<7 | (x&0x3F)<<1
+	if 0 != x&0x100 {
+		code |= 0x1
+	}
+
+	return code
+}
+
+func toyfloatPointEncode3Magnitude(inner float64) uint16 {
+	if inner >= 0.3315972222222222 {
+		if inner >= 0.9947916666666666 {
+			return uint16(32.0*inner*1.0-32.0+0.499999999999) | 0xC0
+		}
+		return uint16(32.0*inner*3.0-32.0+0.499999999999) | 0x80
+	}
+	if inner >= 0.1105324074074074 {
+		return uint16(32.0*inner*9.0-32.0+0.499999999999) | 0x40
+	}
+	return uint16(32.0*inner*27.0-32.0+0.499999999999) | 0x0
+}
+
+// toyfloatPointDecode3 is Decode of the type s9x2b3m-3+layout=xms+reserved=1.2.
+func toyfloatPointDecode3(code uint16) float64 {
+	// The layout xms.
+	p := (code >> 0) & 0x1FF
+	x := (p>>7&0x3)<<6 | p>>1&0x3F | (p>>0&1)<<8
+
+	switch x {
+	case 0x1, 0x100:
+		return math.NaN()
+	}
+
+	v := ((1.0+float64(x&0x3F)*0.03125)*toyfloatPointDecode3Scale[(x>>6)&0x3] - 0.037037037037037035) * 1.0384615384615383
+	if ((1.0 - 1e-14) < v) && (v < (1.0 + 1e-14)) {
+		v = 1.0
+	}
+	if 0 != x&0x100 {
+		return -v
+	}
+	return v
+}
+
+var toyfloatPointDecode3Scale = [4]float64{
+	0.037037037037037035,
+	0.1111111111111111,
+	0.3333333333333333,
+	1.0,
+}
+
+// toyfloatPointEncode4 is Encode of the type u5x3b2m-6.
+func toyfloatPointEncode4(v float64) uint16 {
+	var code uint16
+	switch {
+	case math.IsNaN(v):
+		code = 0x0
+	case v > 3.5396825396825395:
+		code = 0x1F
+	case v < 0:
+		code = 0x0
+	default:
+		code = toyfloatPointEncode4Magnitude(0.015625 + v*0.984375)
+	}
+
+	return code
+}
+
+func toyfloatPointEncode4Magnitude(inner float64) uint16 {
+	if inner >= 0.234375 {
+		if inner >= 0.9375 {
+			if inner >= 1.875 {
+				return uint16(4.0*inner*0.5-4.0+0.499999999999) | 0x1C
+			}
+			return uint16(4.0*inner*1.0-4.0+0.499999999999) | 0x18
+		}
+		if inner >= 0.46875 {
+			return uint16(4.0*inner*2.0-4.0+0.499999999999) | 0x14
+		}
+		return uint16(4.0*inner*4.0-4.0+0.499999999999) | 0x10
+	}
+	if inner >= 0.05859375 {
+		if inner >= 0.1171875 {
+			return uint16(4.0*inner*8.0-4.0+0.499999999999) | 0xC
+		}
+		return uint16(4.0*inner*16.0-4.0+0.499999999999) | 0x8
+	}
+	if inner >= 0.029296875 {
+		return uint16(4.0*inner*32.0-4.0+0.499999999999) | 0x4
+	}
+	return uint16(4.0*inner*64.0-4.0+0.499999999999) | 0x0
+}
+
+// toyfloatPointDecode4 is Decode of the type u5x3b2m-6.
+func toyfloatPointDecode4(code uint16) float64 {
+	x := code & 0x1F
+
+	v := ((1.0+float64(x&0x3)*0.25)*toyfloatPointDecode4Scale[(x>>2)&0x7] - 0.015625) * 1.0158730158730158
+	if ((1.0 - 1e-14) < v) && (v < (1.0 + 1e-14)) {
+		v = 1.0
+	}
+	return v
+}
+
+var toyfloatPointDecode4Scale = [8]float64{
+	0.015625,
+	0.03125,
+	0.0625,
+	0.125,
+	0.25,
+	0.5,
+	1.0,
+	2.0,
+}
@@ -0,0 +1,130 @@
+// Code generated by toyfloatgen -spec s14x4b2m-8+nonfinite+zero=canonical -name Canonical14; DO NOT EDIT.
+
+package specialized
+
+import "math"
+
+// EncodeCanonical14 is Encode of the type s14x4b2m-8+nonfinite+zero=canonical.
+func EncodeCanonical14(v float64) uint16 {
+	var code uint16
+	switch {
+	case math.IsNaN(v):
+		code = 0x1FFE
+	case v > 256.2470588235294:
+		code = 0x1FFD
+		if math.IsInf(v, +1) {
+			code = 0x1FFF
+		}
+	case v < -256.2470588235294:
+		code = 0x3FFD
+		if math.IsInf(v, -1) {
+			code = 0x3FFF
+		}
+	case v < 0:
+		code = 0x2000 | encodeCanonical14Magnitude(0.00390625-v*0.99609375)
+	default:
+		code = encodeCanonical14Magnitude(0.00390625 + v*0.99609375)
+	}
+
+	if code == 0x2000 {
+		code = 0x0
+	}
+
+	return code
+}
+
+func encodeCanonical14Magnitude(inner float64) uint16 {
+	if inner >= 0.99951171875 {
+		if inner >= 15.9921875 {
+			if inner >= 63.96875 {
+				if inner >= 127.9375 {
+					return uint16(512.0*inner*0.0078125-512.0+0.499999999999) | 0x1E00
+				}
+				return uint16(512.0*inner*0.015625-512.0+0.499999999999) | 0x1C00
+			}
+			if inner >= 31.984375 {
+				return uint16(512.0*inner*0.03125-512.0+0.499999999999) | 0x1A00
+			}
+			return uint16(512.0*inner*0.0625-512.0+0.499999999999) | 0x1800
+		}
+		if inner >= 3.998046875 {
+			if inner >= 7.99609375 {
+				return uint16(512.0*inner*0.125-512.0+0.499999999999) | 0x1600
+			}
+			return uint16(512.0*inner*0.25-512.0+0.499999999999) | 0x1400
+		}
+		if inner >= 1.9990234375 {
+			return uint16(512.0*inner*0.5-512.0+0.499999999999) | 0x1200
+		}
+		return uint16(512.0*inner*1.0-512.0+0.499999999999) | 0x1000
+	}
+	if inner >= 0.062469482421875 {
+		if inner >= 0.2498779296875 {
+			if inner >= 0.499755859375 {
+				return uint16(512.0*inner*2.0-512.0+0.499999999999) | 0xE00
+			}
+			return uint16(512.0*inner*4.0-512.0+0.499999999999) | 0xC00
+		}
+		if inner >= 0.12493896484375 {
+			return uint16(512.0*inner*8.0-512.0+0.499999999999) | 0xA00
+		}
+		return uint16(512.0*inner*16.0-512.0+0.499999999999) | 0x800
+	}
+	if inner >= 0.01561737060546875 {
+		if inner >= 0.0312347412109375 {
+			return uint16(512.0*inner*32.0-512.0+0.499999999999) | 0x600
+		}
+		return uint16(512.0*inner*64.0-512.0+0.499999999999) | 0x400
+	}
+	if inner >= 0.007808685302734375 {
+		return uint16(512.0*inner*128.0-512.0+0.499999999999) | 0x200
+	}
+	return uint16(512.0*inner*256.0-512.0+0.499999999999) | 0x0
+}
+
+// DecodeCanonical14 is Decode of the type s14x4b2m-8+nonfinite+zero=canonical.
+func DecodeCanonical14(code uint16) float64 {
+	x := code & 0x3FFF
+
+	if x == 0x2000 {
+		return 0.0
+	}
+
+	switch x & 0x1FFF {
+	case 0x1FFF:
+		if 0 != x&0x2000 {
+			return math.Inf(-1)
+		}
+		return math.Inf(+1)
+	case 0x1FFE:
+		return math.NaN()
+	}
+
+	v := ((1.0+float64(x&0x1FF)*0.001953125)*decodeCanonical14Scale[(x>>9)&0xF] - 0.00390625) * 1.003921568627451
+	if ((1.0 - 1e-14) < v) && (v < (1.0 + 1e-14)) {
+		v = 1.0
+	}
+	if 0 != x&0x2000 {
+		return -v
+	}
+	return v
+}
+
+var decodeCanonical14Scale = [16]float64{
+	0.00390625,
+	0.0078125,
+	0.015625,
+	0.03125,
+	0.0625,
+	0.125,
+	0.25,
+	0.5,
+	1.0,
+	2.0,
+	4.0,
+	8.0,
+	16.0,
+	32.0,
+	64.0,
+	128.0,
+}
@@ -0,0 +1,100 @@
+// Code generated by toyfloatgen -spec s9x2b3m-3+reserved=1.2.1ff+layout=xms+protect=parity -name Reserved9; DO NOT EDIT.
+
+package specialized
+
+import "math"
+
+// EncodeReserved9 is Encode of the type s9x2b3m-3+layout=xms+protect=parity+reserved=1.2.1ff.
+func EncodeReserved9(v float64) uint16 {
+	var code uint16
+	switch {
+	case math.IsNaN(v):
+		code = 0x0
+	case v > 3.0444711538461533:
+		code = 0xFF
+	case v < -3.0444711538461533:
+		code = 0x1FF
+	case v < 0:
+		code = 0x100 | encodeReserved9Magnitude(0.037037037037037035-v*0.962962962962963)
+	default:
+		code = encodeReserved9Magnitude(0.037037037037037035 + v*0.962962962962963)
+	}
+
+	// Reserved codes.
+	switch code {
+	case 0x1:
+		if math.Abs(0.0-v) < math.Abs(0.0024038461538461496-v) {
+			code = 0x0
+		} else {
+			code = 0x2
+		}
+	case 0x100:
+		if math.Abs(-0.0012019230769230748-v) < math.Abs(0.0-v) {
+			code = 0x101
+		} else {
+			code = 0x0
+		}
+	case 0x1FF:
+		code = 0x1FE
+	}
+
+	// The layout xms.
+	x := code & 0x1FF
+	code = (x>>6&0x3)<<7 | (x&0x3F)<<1
+	if 0 != x&0x100 {
+		code |= 0x1
+	}
+
+	// The check bits of parity.
+	value := (code >> 0) & 0x1FF
+	check := uint16(0)
+	for i, column := range encodeReserved9Check {
+		if 0 != (value>>uint(i))&1 {
+			check ^= column
+		}
+	}
+	return code&0x1FF | (check<<9)&0xFE00
+}
+
+func encodeReserved9Magnitude(inner float64) uint16 {
+	if inner >= 0.3315972222222222 {
+		if inner >= 0.9947916666666666 {
+			return uint16(32.0*inner*1.0-32.0+0.499999999999) | 0xC0
+		}
+		return uint16(32.0*inner*3.0-32.0+0.499999999999) | 0x80
+	}
+	if inner >= 0.1105324074074074 {
+		return uint16(32.0*inner*9.0-32.0+0.499999999999) | 0x40
+	}
+	return uint16(32.0*inner*27.0-32.0+0.499999999999) | 0x0
+}
+
+var encodeReserved9Check = [9]uint16{0x1, 0x1, 0x1, 0x1, 0x1, 0x1, 0x1, 0x1, 0x1}
+
+// DecodeReserved9 is Decode of the type s9x2b3m-3+layout=xms+protect=parity+reserved=1.2.1ff.
+func DecodeReserved9(code uint16) float64 {
+	// The layout xms.
+	p := (code >> 0) & 0x1FF
+	x := (p>>7&0x3)<<6 | p>>1&0x3F | (p>>0&1)<<8
+
+	switch x {
+	case 0x1, 0x100, 0x1FF:
+		return math.NaN()
+	}
+
+	v := ((1.0+float64(x&0x3F)*0.03125)*decodeReserved9Scale[(x>>6)&0x3] - 0.037037037037037035) * 1.0384615384615383
+	if ((1.0 - 1e-14) < v) && (v < (1.0 + 1e-14)) {
+		v = 1.0
+	}
+	if 0 != x&0x100 {
+		return -v
+	}
+	return v
+}
+
+var decodeReserved9Scale = [4]float64{
+	0.037037037037037035,
+	0.1111111111111111,
+	0.3333333333333333,
+	1.0,
+}
@@ -0,0 +1,10 @@
+// Package specialized has the functions that toyfloatgen generates for types.
+// Its tests compare them with the methods of the types.
+package specialized
+
+//go:generate go run github.com/georgy7/toyfloat/cmd/toyfloatgen -spec 12 -name X12
+//go:generate go run github.com/georgy7/toyfloat/cmd/toyfloatgen -spec 16x2 -name X16x2
+//go:generate go run github.com/georgy7/toyfloat/cmd/toyfloatgen -spec 8x3u -name U8x3
+//go:generate go run github.com/georgy7/toyfloat/cmd/toyfloatgen -spec s14x4b2m-8+nonfinite+zero=canonical -name Canonical14
+//go:generate go run github.com/georgy7/toyfloat/cmd/toyfloatgen -spec s10x3b2m-6+nonfinite+zero=marker+twos+layout=sxm.msb+protect=crc4 -name Twos10
+//go:generate go run github.com/georgy7/toyfloat/cmd/toyfloatgen -spec s9x2b3m-3+reserved=1.2.1ff+layout=xms+protect=parity -name Reserved9
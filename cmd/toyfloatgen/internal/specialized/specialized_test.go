@@ -0,0 +1,184 @@
+package specialized
+
+import (
+	"math"
+	"testing"
+
+	"github.com/georgy7/toyfloat"
+)
+
+var floatResult float64
+var intResult int
+
+type generated struct {
+	spec   string
+	encode func(float64) uint16
+	decode func(uint16) float64
+}
+
+var functions = []generated{
+	{"12", EncodeX12, DecodeX12},
+	{"16x2", EncodeX16x2, DecodeX16x2},
+	{"8x3u", EncodeU8x3, DecodeU8x3},
+	{"s14x4b2m-8+nonfinite+zero=canonical", EncodeCanonical14, DecodeCanonical14},
+	{"s10x3b2m-6+nonfinite+zero=marker+twos+layout=sxm.msb+protect=crc4",
+		EncodeTwos10, DecodeTwos10},
+	{"s9x2b3m-3+reserved=1.2.1ff+layout=xms+protect=parity",
+		EncodeReserved9, DecodeReserved9},
+}
+
+func makeType(spec string, t testing.TB) toyfloat.Type {
+	tf, err := toyfloat.ParseTag(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tf
+}
+
+func same(a, b float64) bool {
+	return math.Float64bits(a) == math.Float64bits(b) || (math.IsNaN(a) && math.IsNaN(b))
+}
+
+// TestAllCodes decodes every 16-bit code, including extra bits,
+// and encodes the results back.
+func TestAllCodes(t *testing.T) {
+	for _, f := range functions {
+		tf := makeType(f.spec, t)
+
+		for i := 0; i <= 0xFFFF; i++ {
+			code := uint16(i)
+			v := tf.Decode(code)
+
+			if r := f.decode(code); !same(r, v) {
+				t.Fatalf("%s: 0x%X -> %v, not %v", f.spec, code, r, v)
+			}
+
+			if r := f.encode(v); r != tf.Encode(v) {
+				t.Fatalf("%s: %v -> 0x%X, not 0x%X", f.spec, v, r, tf.Encode(v))
+			}
+		}
+	}
+}
+
+// TestRounding encodes the values around the boundaries
+// of the rounding bins, and a sweep of the whole range.
+func TestRounding(t *testing.T) {
+	for _, f := range functions {
+		tf := makeType(f.spec, t)
+
+		check := func(v float64) {
+			if r := f.encode(v); r != tf.Encode(v) {
+				t.Fatalf("%s: %v -> 0x%X, not 0x%X", f.spec, v, r, tf.Encode(v))
+			}
+		}
+
+		bits := tf.Params().Length
+		for i := 0; i < (1<<bits)-1; i++ {
+			a := tf.Decode(tf.FromComparable(uint16(i)))
+			b := tf.Decode(tf.FromComparable(uint16(i + 1)))
+			if math.IsNaN(a) || math.IsNaN(b) {
+				continue
+			}
+
+			middle := a + (b-a)/2
+			for _, v := range []float64{a, b, middle,
+				math.Nextafter(middle, math.Inf(-1)), math.Nextafter(middle, math.Inf(+1)),
+				math.Nextafter(a, math.Inf(+1)), math.Nextafter(b, math.Inf(-1))} {
+
+				check(v)
+			}
+		}
+
+		for _, v := range []float64{math.Inf(+1), math.Inf(-1), math.NaN(),
+			math.Copysign(0, -1), math.MaxFloat64, -math.MaxFloat64,
+			math.SmallestNonzeroFloat64, -math.SmallestNonzeroFloat64} {
+
+			check(v)
+		}
+
+		for v := -300.0; v <= 300.0; v += 0.0037 {
+			check(v)
+		}
+	}
+}
+
+// TestRoundTrip is the round trip of the dynamic types,
+// TestNonFiniteRoundTrip for example: each code of a finite value
+// except -0 is encoded back to itself.
+func TestRoundTrip(t *testing.T) {
+	for _, f := range functions {
+		tf := makeType(f.spec, t)
+		bits := tf.Params().Length
+
+		for i := 0; i < 1<<bits; i++ {
+			code := tf.FromComparable(uint16(i))
+			if tf.ToComparable(code) != uint16(i) {
+				continue // the comparable zero of CanonicalZero types
+			}
+
+			v := f.decode(code)
+			if math.IsNaN(v) || (v == 0 && math.Signbit(v)) {
+				continue
+			}
+
+			// FromComparable may leave extra bits.
+			if r := f.encode(v); tf.ToComparable(r) != uint16(i) {
+				t.Fatalf("%s: 0x%X -> %v -> 0x%X", f.spec, code, v, r)
+			}
+		}
+	}
+}
+
+func BenchmarkEncodeGenerated(b *testing.B) {
+	r := 0
+	for i := 0; i < b.N; i++ {
+		r += int(EncodeX12(float64(i%512) - 255.5))
+	}
+	intResult = r
+}
+
+func BenchmarkEncodeType(b *testing.B) {
+	tf := makeType("12", b)
+
+	r := 0
+	for i := 0; i < b.N; i++ {
+		r += int(tf.Encode(float64(i%512) - 255.5))
+	}
+	intResult = r
+}
+
+func BenchmarkDecodeGenerated(b *testing.B) {
+	r := 0.0
+	for i := 0; i < b.N; i++ {
+		r += DecodeX12(uint16(i))
+	}
+	floatResult = r
+}
+
+func BenchmarkDecodeType(b *testing.B) {
+	tf := makeType("12", b)
+
+	r := 0.0
+	for i := 0; i < b.N; i++ {
+		r += tf.Decode(uint16(i))
+	}
+	floatResult = r
+}
+
+func BenchmarkEncodeGeneratedTwos(b *testing.B) {
+	r := 0
+	for i := 0; i < b.N; i++ {
+		r += int(EncodeTwos10(float64(i%8) - 3.5))
+	}
+	intResult = r
+}
+
+func BenchmarkEncodeTypeTwos(b *testing.B) {
+	tf := makeType(functions[4].spec, b)
+
+	r := 0
+	for i := 0; i < b.N; i++ {
+		r += int(tf.Encode(float64(i%8) - 3.5))
+	}
+	intResult = r
+}
@@ -0,0 +1,146 @@
+// Code generated by toyfloatgen -spec s10x3b2m-6+nonfinite+zero=marker+twos+layout=sxm.msb+protect=crc4 -name Twos10; DO NOT EDIT.
+
+package specialized
+
+import "math"
+
+// EncodeTwos10 is Encode of the type s10x3b2m-6+nonfinite+zero=marker+twos+layout=sxm.msb+protect=crc4.
+func EncodeTwos10(v float64) uint16 {
+	var code uint16
+	switch {
+	case math.IsNaN(v):
+		code = 0x1FE
+	case v > 3.952380952380952:
+		code = 0x1FD
+		if math.IsInf(v, +1) {
+			code = 0x1FF
+		}
+	case v < -3.952380952380952:
+		code = 0x3FD
+		if math.IsInf(v, -1) {
+			code = 0x3FF
+		}
+	case v < 0:
+		code = 0x200 | encodeTwos10Magnitude(0.015625-v*0.984375)
+	default:
+		code = encodeTwos10Magnitude(0.015625 + v*0.984375)
+	}
+
+	if code == 0x200 {
+		code = 0x0
+	}
+
+	// Two's complement.
+	if 0 == code&0x200 {
+		code |= 0x200
+	} else {
+		if code == 0x200 {
+			code = 0x0
+		} else {
+			code = ^code + 1
+		}
+	}
+	code = (code & 0x3FF) ^ 0x200
+	if 0 != code&0x200 {
+		code |= 0xFC00
+	}
+
+	// The layout sxm.msb.
+	x := code & 0x3FF
+	code = (x>>6&0x7)<<6 | (x&0x3F)<<0
+	if 0 != x&0x200 {
+		code |= 0x200
+	}
+	code <<= 6
+
+	// The check bits of crc4.
+	value := (code >> 6) & 0x3FF
+	check := uint16(0)
+	for i, column := range encodeTwos10Check {
+		if 0 != (value>>uint(i))&1 {
+			check ^= column
+		}
+	}
+	return code&0xFFC0 | (check<<0)&0x3F
+}
+
+func encodeTwos10Magnitude(inner float64) uint16 {
+	if inner >= 0.2490234375 {
+		if inner >= 0.99609375 {
+			if inner >= 1.9921875 {
+				return uint16(64.0*inner*0.5-64.0+0.499999999999) | 0x1C0
+			}
+			return uint16(64.0*inner*1.0-64.0+0.499999999999) | 0x180
+		}
+		if inner >= 0.498046875 {
+			return uint16(64.0*inner*2.0-64.0+0.499999999999) | 0x140
+		}
+		return uint16(64.0*inner*4.0-64.0+0.499999999999) | 0x100
+	}
+	if inner >= 0.062255859375 {
+		if inner >= 0.12451171875 {
+			return uint16(64.0*inner*8.0-64.0+0.499999999999) | 0xC0
+		}
+		return uint16(64.0*inner*16.0-64.0+0.499999999999) | 0x80
+	}
+	if inner >= 0.0311279296875 {
+		return uint16(64.0*inner*32.0-64.0+0.499999999999) | 0x40
+	}
+	return uint16(64.0*inner*64.0-64.0+0.499999999999) | 0x0
+}
+
+var encodeTwos10Check = [10]uint16{0xB, 0xF, 0x7, 0xE, 0x5, 0xA, 0xD, 0x3, 0x6, 0xC}
+
+// DecodeTwos10 is Decode of the type s10x3b2m-6+nonfinite+zero=marker+twos+layout=sxm.msb+protect=crc4.
+func DecodeTwos10(code uint16) float64 {
+	// The layout sxm.msb.
+	p := (code >> 6) & 0x3FF
+	x := (p>>6&0x7)<<6 | p>>0&0x3F | (p>>9&1)<<9
+
+	// Two's complement.
+	c := (x ^ 0x200) & 0x3FF
+	if 0 == c&0x200 {
+		if 0 == c {
+			x = 0x200
+		} else {
+			x = ^(c - 1)
+		}
+	} else {
+		x = c &^ 0x200
+	}
+	x &= 0x3FF
+
+	if x == 0x200 {
+		return math.NaN()
+	}
+
+	switch x & 0x1FF {
+	case 0x1FF:
+		if 0 != x&0x200 {
+			return math.Inf(-1)
+		}
+		return math.Inf(+1)
+	case 0x1FE:
+		return math.NaN()
+	}
+
+	v := ((1.0+float64(x&0x3F)*0.015625)*decodeTwos10Scale[(x>>6)&0x7] - 0.015625) * 1.0158730158730158
+	if ((1.0 - 1e-14) < v) && (v < (1.0 + 1e-14)) {
+		v = 1.0
+	}
+	if 0 != x&0x200 {
+		return -v
+	}
+	return v
+}
+
+var decodeTwos10Scale = [8]float64{
+	0.015625,
+	0.03125,
+	0.0625,
+	0.125,
+	0.25,
+	0.5,
+	1.0,
+	2.0,
+}
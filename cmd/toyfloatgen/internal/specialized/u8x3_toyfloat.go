@@ -0,0 +1,69 @@
+// Code generated by toyfloatgen -spec 8x3u -name U8x3; DO NOT EDIT.
+
+package specialized
+
+import "math"
+
+// EncodeU8x3 is Encode of the type u8x3b2m-6.
+func EncodeU8x3(v float64) uint16 {
+	var code uint16
+	switch {
+	case math.IsNaN(v):
+		code = 0x0
+	case v > 3.9841269841269837:
+		code = 0xFF
+	case v < 0:
+		code = 0x0
+	default:
+		code = encodeU8x3Magnitude(0.015625 + v*0.984375)
+	}
+
+	return code
+}
+
+func encodeU8x3Magnitude(inner float64) uint16 {
+	if inner >= 0.248046875 {
+		if inner >= 0.9921875 {
+			if inner >= 1.984375 {
+				return uint16(32.0*inner*0.5-32.0+0.499999999999) | 0xE0
+			}
+			return uint16(32.0*inner*1.0-32.0+0.499999999999) | 0xC0
+		}
+		if inner >= 0.49609375 {
+			return uint16(32.0*inner*2.0-32.0+0.499999999999) | 0xA0
+		}
+		return uint16(32.0*inner*4.0-32.0+0.499999999999) | 0x80
+	}
+	if inner >= 0.06201171875 {
+		if inner >= 0.1240234375 {
+			return uint16(32.0*inner*8.0-32.0+0.499999999999) | 0x60
+		}
+		return uint16(32.0*inner*16.0-32.0+0.499999999999) | 0x40
+	}
+	if inner >= 0.031005859375 {
+		return uint16(32.0*inner*32.0-32.0+0.499999999999) | 0x20
+	}
+	return uint16(32.0*inner*64.0-32.0+0.499999999999) | 0x0
+}
+
+// DecodeU8x3 is Decode of the type u8x3b2m-6.
+func DecodeU8x3(code uint16) float64 {
+	x := code & 0xFF
+
+	v := ((1.0+float64(x&0x1F)*0.03125)*decodeU8x3Scale[(x>>5)&0x7] - 0.015625) * 1.0158730158730158
+	if ((1.0 - 1e-14) < v) && (v < (1.0 + 1e-14)) {
+		v = 1.0
+	}
+	return v
+}
+
+var decodeU8x3Scale = [8]float64{
+	0.015625,
+	0.03125,
+	0.0625,
+	0.125,
+	0.25,
+	0.5,
+	1.0,
+	2.0,
+}
@@ -0,0 +1,106 @@
+// Code generated by toyfloatgen -spec 12 -name X12; DO NOT EDIT.
+
+package specialized
+
+import "math"
+
+// EncodeX12 is Encode of the type s12x4b2m-8.
+func EncodeX12(v float64) uint16 {
+	var code uint16
+	switch {
+	case math.IsNaN(v):
+		code = 0x0
+	case v > 255.99607843137255:
+		code = 0x7FF
+	case v < -255.99607843137255:
+		code = 0xFFF
+	case v < 0:
+		code = 0x800 | encodeX12Magnitude(0.00390625-v*0.99609375)
+	default:
+		code = encodeX12Magnitude(0.00390625 + v*0.99609375)
+	}
+
+	return code
+}
+
+func encodeX12Magnitude(inner float64) uint16 {
+	if inner >= 0.998046875 {
+		if inner >= 15.96875 {
+			if inner >= 63.875 {
+				if inner >= 127.75 {
+					return uint16(128.0*inner*0.0078125-128.0+0.499999999999) | 0x780
+				}
+				return uint16(128.0*inner*0.015625-128.0+0.499999999999) | 0x700
+			}
+			if inner >= 31.9375 {
+				return uint16(128.0*inner*0.03125-128.0+0.499999999999) | 0x680
+			}
+			return uint16(128.0*inner*0.0625-128.0+0.499999999999) | 0x600
+		}
+		if inner >= 3.9921875 {
+			if inner >= 7.984375 {
+				return uint16(128.0*inner*0.125-128.0+0.499999999999) | 0x580
+			}
+			return uint16(128.0*inner*0.25-128.0+0.499999999999) | 0x500
+		}
+		if inner >= 1.99609375 {
+			return uint16(128.0*inner*0.5-128.0+0.499999999999) | 0x480
+		}
+		return uint16(128.0*inner*1.0-128.0+0.499999999999) | 0x400
+	}
+	if inner >= 0.0623779296875 {
+		if inner >= 0.24951171875 {
+			if inner >= 0.4990234375 {
+				return uint16(128.0*inner*2.0-128.0+0.499999999999) | 0x380
+			}
+			return uint16(128.0*inner*4.0-128.0+0.499999999999) | 0x300
+		}
+		if inner >= 0.124755859375 {
+			return uint16(128.0*inner*8.0-128.0+0.499999999999) | 0x280
+		}
+		return uint16(128.0*inner*16.0-128.0+0.499999999999) | 0x200
+	}
+	if inner >= 0.015594482421875 {
+		if inner >= 0.03118896484375 {
+			return uint16(128.0*inner*32.0-128.0+0.499999999999) | 0x180
+		}
+		return uint16(128.0*inner*64.0-128.0+0.499999999999) | 0x100
+	}
+	if inner >= 0.0077972412109375 {
+		return uint16(128.0*inner*128.0-128.0+0.499999999999) | 0x80
+	}
+	return uint16(128.0*inner*256.0-128.0+0.499999999999) | 0x0
+}
+
+// DecodeX12 is Decode of the type s12x4b2m-8.
+func DecodeX12(code uint16) float64 {
+	x := code & 0xFFF
+
+	v := ((1.0+float64(x&0x7F)*0.0078125)*decodeX12Scale[(x>>7)&0xF] - 0.00390625) * 1.003921568627451
+	if ((1.0 - 1e-14) < v) && (v < (1.0 + 1e-14)) {
+		v = 1.0
+	}
+	if 0 != x&0x800 {
+		return -v
+	}
+	return v
+}
+
+var decodeX12Scale = [16]float64{
+	0.00390625,
+	0.0078125,
+	0.015625,
+	0.03125,
+	0.0625,
+	0.125,
+	0.25,
+	0.5,
+	1.0,
+	2.0,
+	4.0,
+	8.0,
+	16.0,
+	32.0,
+	64.0,
+	128.0,
+}
@@ -0,0 +1,58 @@
+// Code generated by toyfloatgen -spec 16x2 -name X16x2; DO NOT EDIT.
+
+package specialized
+
+import "math"
+
+// EncodeX16x2 is Encode of the type s16x2b3m-3.
+func EncodeX16x2(v float64) uint16 {
+	var code uint16
+	switch {
+	case math.IsNaN(v):
+		code = 0x0
+	case v > 3.076669546274038:
+		code = 0x7FFF
+	case v < -3.076669546274038:
+		code = 0xFFFF
+	case v < 0:
+		code = 0x8000 | encodeX16x2Magnitude(0.037037037037037035-v*0.962962962962963)
+	default:
+		code = encodeX16x2Magnitude(0.037037037037037035 + v*0.962962962962963)
+	}
+
+	return code
+}
+
+func encodeX16x2Magnitude(inner float64) uint16 {
+	if inner >= 0.33331976996527773 {
+		if inner >= 0.9999593098958333 {
+			return uint16(4096.0*inner*1.0-4096.0+0.499999999999) | 0x6000
+		}
+		return uint16(4096.0*inner*3.0-4096.0+0.499999999999) | 0x4000
+	}
+	if inner >= 0.11110658998842592 {
+		return uint16(4096.0*inner*9.0-4096.0+0.499999999999) | 0x2000
+	}
+	return uint16(4096.0*inner*27.0-4096.0+0.499999999999) | 0x0
+}
+
+// DecodeX16x2 is Decode of the type s16x2b3m-3.
+func DecodeX16x2(code uint16) float64 {
+	x := code & 0xFFFF
+
+	v := ((1.0+float64(x&0x1FFF)*0.000244140625)*decodeX16x2Scale[(x>>13)&0x3] - 0.037037037037037035) * 1.0384615384615383
+	if ((1.0 - 1e-14) < v) && (v < (1.0 + 1e-14)) {
+		v = 1.0
+	}
+	if 0 != x&0x8000 {
+		return -v
+	}
+	return v
+}
+
+var decodeX16x2Scale = [4]float64{
+	0.037037037037037035,
+	0.1111111111111111,
+	0.3333333333333333,
+	1.0,
+}
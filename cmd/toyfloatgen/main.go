@@ -0,0 +1,92 @@
+// Toyfloatgen generates Go code, that packs structs
+// the way toyfloat.Marshal does, but without reflection.
+//
+// Usage:
+//
+//	//go:generate toyfloatgen -type Point,Track
+//
+// It reads the package in the current directory, and writes
+// MarshalToyfloat and UnmarshalToyfloat methods of the types
+// to point_toyfloat.go, which is named after the first type.
+// The output is byte-identical to toyfloat.Marshal,
+// and the errors are the same. Both need the sizes
+// of int, uint and uintptr fields in their tags,
+// since they depend on the platform.
+// Run it once per package, or give the runs different first types.
+//
+// Without structs, it writes a file with two functions for one type:
+//
+//	//go:generate toyfloatgen -spec s12x4b2m-8 -name X12
+//
+// EncodeX12 and DecodeX12 return exactly what the methods Encode and Decode
+// of the type return, but they are faster, since the constants are folded.
+// The type is anything a float field tag can be: a spec, a preset name,
+// or "x4,12,signed". The file is named after the functions, x12_toyfloat.go.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of struct names")
+	spec := flag.String("spec", "", "type of the functions, instead of -type")
+	name := flag.String("name", "", "suffix of the function names, with -spec")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package name, with -spec")
+	output := flag.String("output", "", "output file name")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 1 {
+		usage()
+	} else if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+
+	if "" != *spec {
+		if ("" != *typeNames) || ("" == *name) || ("" == *pkg) {
+			usage()
+		}
+
+		source, err := generateType(*spec, *name, *pkg)
+		exitOnError(err)
+
+		if "" == *output {
+			*output = filepath.Join(dir, strings.ToLower(*name)+"_toyfloat.go")
+		}
+		exitOnError(ioutil.WriteFile(*output, source, 0644))
+		return
+	}
+
+	if "" == *typeNames {
+		usage()
+	}
+	types := strings.Split(*typeNames, ",")
+
+	source, err := generateStructs(dir, types)
+	exitOnError(err)
+
+	if "" == *output {
+		*output = filepath.Join(dir, strings.ToLower(types[0])+"_toyfloat.go")
+	}
+	exitOnError(ioutil.WriteFile(*output, source, 0644))
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: toyfloatgen -type T1,T2 [-output file] [dir]\n")
+	fmt.Fprintf(os.Stderr, "       toyfloatgen -spec spec -name Name"+
+		" [-package name] [-output file] [dir]\n")
+	os.Exit(2)
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "toyfloatgen: %v\n", err)
+		os.Exit(1)
+	}
+}
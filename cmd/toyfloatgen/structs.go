@@ -0,0 +1,536 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/georgy7/toyfloat"
+)
+
+type fieldKind uint8
+
+const (
+	floatField fieldKind = iota
+	intField
+	uintField
+	boolField
+	structField
+	arrayField
+)
+
+// field is planned the same way toyfloat.Marshal plans it.
+type field struct {
+	name   string // as toyfloat.Marshal reports it
+	path   string // selector of a struct field, such as ".Points"
+	goType string // for conversions
+	kind   fieldKind
+	bits   int
+	narrow bool    // integers narrower than their Go type are checked
+	tf     int     // index of the float type
+	fields []field // of a struct, or the element of an array
+	length int     // of an array
+}
+
+type structPlan struct {
+	name string
+	root field
+	bits int
+}
+
+type generator struct {
+	pkg     string
+	decls   map[string]*ast.TypeSpec
+	prefix  string
+	command string
+
+	structs []structPlan
+	types   []toyfloat.Type
+	indices map[string]int // by spec
+	bools   bool
+}
+
+// generateStructs returns the source of the methods of the structs.
+func generateStructs(dir string, names []string) ([]byte, error) {
+	g := &generator{
+		decls:   make(map[string]*ast.TypeSpec),
+		prefix:  "toyfloat" + names[0],
+		command: "toyfloatgen -type " + strings.Join(names, ","),
+		indices: make(map[string]int),
+	}
+
+	if err := g.parsePackage(dir); err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		decl, ok := g.decls[name]
+		if !ok {
+			return nil, fmt.Errorf("type %s is not found", name)
+		}
+
+		st, ok := decl.Type.(*ast.StructType)
+		if !ok {
+			return nil, fmt.Errorf("type %s is not a struct", name)
+		}
+
+		plan := structPlan{name: name}
+		root, err := g.planStruct(st, name, &plan.bits)
+		if err != nil {
+			return nil, err
+		}
+
+		plan.root = root
+		g.structs = append(g.structs, plan)
+	}
+
+	return g.source()
+}
+
+func (g *generator) parsePackage(dir string) error {
+	fset := token.NewFileSet()
+	notTest := func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}
+
+	packages, err := parser.ParseDir(fset, dir, notTest, 0)
+	if err != nil {
+		return err
+	} else if len(packages) != 1 {
+		return fmt.Errorf("%d packages in %s", len(packages), dir)
+	}
+
+	for name, pkg := range packages {
+		g.pkg = name
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || (token.TYPE != gen.Tok) {
+					continue
+				}
+
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					g.decls[ts.Name.Name] = ts
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ----------------
+// Planning:
+
+func (g *generator) planStruct(st *ast.StructType, name string, bits *int) (field, error) {
+	r := field{name: name, kind: structField}
+	for _, f := range st.Fields.List {
+		tag, tagged := "", false
+		if nil != f.Tag {
+			literal, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return r, err
+			}
+			tag, tagged = reflect.StructTag(literal).Lookup("toyfloat")
+		}
+
+		names := make([]string, 0, len(f.Names))
+		for _, ident := range f.Names {
+			names = append(names, ident.Name)
+		}
+		if 0 == len(names) {
+			// Embedded.
+			names = append(names, embeddedName(f.Type))
+		}
+
+		for _, fieldName := range names {
+			if !ast.IsExported(fieldName) || (tag == "-") {
+				// Unexported or skipped.
+				continue
+			}
+
+			planned, err := g.planField(f.Type, types.ExprString(f.Type),
+				name+"."+fieldName, tag, tagged, bits)
+			if err != nil {
+				return r, err
+			}
+
+			planned.path = "." + fieldName
+			r.fields = append(r.fields, planned)
+		}
+	}
+	return r, nil
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+var intBits = map[string]int{
+	"int8": 8, "int16": 16, "int32": 32, "int64": 64, "rune": 32,
+	"uint8": 8, "uint16": 16, "uint32": 32, "uint64": 64, "byte": 8,
+
+	// Their sizes depend on the platform.
+	"int": 0, "uint": 0, "uintptr": 0,
+}
+
+func (g *generator) planField(expr ast.Expr, goType, name, tag string,
+	tagged bool, bits *int) (field, error) {
+
+	f := field{name: name, goType: goType}
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return g.planField(e.X, goType, name, tag, tagged, bits)
+
+	case *ast.StructType:
+		if tagged {
+			return f, fmt.Errorf("field %s: %w", name, toyfloat.ErrInvalidTag)
+		}
+		return g.planStruct(e, name, bits)
+
+	case *ast.ArrayType:
+		literal, ok := e.Len.(*ast.BasicLit)
+		if !ok || (token.INT != literal.Kind) {
+			return f, fmt.Errorf("field %s of type %s: %w",
+				name, goType, toyfloat.ErrUnsupportedField)
+		}
+
+		length, err := strconv.Atoi(literal.Value)
+		if err != nil {
+			return f, err
+		}
+
+		element, err := g.planField(e.Elt, types.ExprString(e.Elt),
+			name+"[]", tag, tagged, bits)
+		if err != nil {
+			return f, err
+		}
+
+		// The element counted itself once.
+		*bits += (length - 1) * fieldBits(&element)
+
+		f.kind = arrayField
+		f.fields = []field{element}
+		f.length = length
+		return f, nil
+
+	case *ast.Ident:
+		return g.planIdent(e, goType, name, tag, tagged, bits)
+	}
+
+	return f, fmt.Errorf("field %s of type %s: %w",
+		name, goType, toyfloat.ErrUnsupportedField)
+}
+
+func (g *generator) planIdent(e *ast.Ident, goType, name, tag string,
+	tagged bool, bits *int) (field, error) {
+
+	f := field{name: name, goType: goType}
+	size, isInt := intBits[e.Name]
+
+	switch {
+	case (e.Name == "float32") || (e.Name == "float64"):
+		if !tagged {
+			return f, fmt.Errorf("field %s has no type: %w", name, toyfloat.ErrInvalidTag)
+		}
+
+		tf, err := toyfloat.ParseTag(tag)
+		if err != nil {
+			return f, fmt.Errorf("field %s, tag %q: %w", name, tag, err)
+		}
+
+		f.kind = floatField
+		f.tf = g.addType(tf)
+		f.bits = int(tf.Params().Length)
+
+	case e.Name == "bool":
+		if tagged {
+			return f, fmt.Errorf("field %s: %w", name, toyfloat.ErrInvalidTag)
+		}
+		f.kind = boolField
+		f.bits = 1
+		g.bools = true
+
+	case isInt:
+		f.kind = intField
+		if strings.HasPrefix(e.Name, "u") || (e.Name == "byte") {
+			f.kind = uintField
+		}
+
+		if tagged {
+			n, err := strconv.Atoi(tag)
+			if (err != nil) || (n < 1) || ((0 != size) && (n > size)) || (n > 64) {
+				return f, fmt.Errorf("field %s, tag %q: %w", name, tag, toyfloat.ErrInvalidTag)
+			}
+			f.narrow = (n < 64) && ((0 == size) || (n < size))
+			size = n
+		} else if 0 == size {
+			return f, fmt.Errorf("field %s of type %s needs its size in the tag: %w",
+				name, goType, toyfloat.ErrInvalidTag)
+		}
+		f.bits = size
+
+	default:
+		decl, ok := g.decls[e.Name]
+		if !ok {
+			return f, fmt.Errorf("field %s of type %s: %w",
+				name, goType, toyfloat.ErrUnsupportedField)
+		}
+		return g.planField(decl.Type, goType, name, tag, tagged, bits)
+	}
+
+	*bits += f.bits
+	return f, nil
+}
+
+func (g *generator) addType(tf toyfloat.Type) int {
+	spec := tf.Spec()
+	if i, ok := g.indices[spec]; ok {
+		return i
+	}
+
+	g.indices[spec] = len(g.types)
+	g.types = append(g.types, tf)
+	return len(g.types) - 1
+}
+
+func fieldBits(f *field) int {
+	switch f.kind {
+	case structField:
+		n := 0
+		for i := range f.fields {
+			n += fieldBits(&f.fields[i])
+		}
+		return n
+	case arrayField:
+		return f.length * fieldBits(&f.fields[0])
+	}
+	return f.bits
+}
+
+// ----------------
+// Writing:
+
+func (g *generator) source() ([]byte, error) {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Code generated by %s; DO NOT EDIT.\n\n", g.command)
+	fmt.Fprintf(&b, "package %s\n\n", g.pkg)
+	fmt.Fprintf(&b, "import (\n\"fmt\"\n")
+	if len(g.types) > 0 {
+		fmt.Fprintf(&b, "\"math\"\n")
+	}
+	fmt.Fprintf(&b, "\n\"github.com/georgy7/toyfloat\"\n)\n\n")
+
+	for i := range g.structs {
+		g.writeMarshal(&b, &g.structs[i])
+		g.writeUnmarshal(&b, &g.structs[i])
+	}
+
+	g.writeHelpers(&b)
+
+	for i, tf := range g.types {
+		err := toyfloat.WriteGo(&b, tf, g.encoder(i), g.decoder(i))
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+
+	return format.Source(b.Bytes())
+}
+
+func (g *generator) encoder(i int) string {
+	return fmt.Sprintf("%sEncode%d", g.prefix, i)
+}
+
+func (g *generator) decoder(i int) string {
+	return fmt.Sprintf("%sDecode%d", g.prefix, i)
+}
+
+func (g *generator) writeMarshal(b *bytes.Buffer, plan *structPlan) {
+	fmt.Fprintf(b, "// MarshalToyfloat packs the struct the way toyfloat.Marshal does.\n")
+	fmt.Fprintf(b, "func (v *%s) MarshalToyfloat() ([]byte, error) {\n", plan.name)
+	fmt.Fprintf(b, "data := make([]byte, %d)\n", (plan.bits+7)/8)
+	if plan.bits > 0 {
+		fmt.Fprintf(b, "o := uint(0)\n\n")
+		g.writePut(b, &plan.root, "v", 0)
+	}
+	fmt.Fprintf(b, "return data, nil\n}\n\n")
+}
+
+func (g *generator) writePut(b *bytes.Buffer, f *field, expr string, depth int) {
+	switch f.kind {
+	case floatField:
+		tf := g.types[f.tf]
+		align := 0
+		if tf.Layout().AlignMSB {
+			align = 16 - f.bits
+		}
+
+		code := fmt.Sprintf("%s(%s)", g.encoder(f.tf), convert("float64", f.goType, expr))
+		if 0 != align {
+			code = fmt.Sprintf("(%s >> %d)", code, align)
+		}
+
+		fmt.Fprintf(b, "o = %sPut(data, o, uint64(%s&0x%X), %d)\n",
+			g.prefix, code, (uint64(1)<<f.bits)-1, f.bits)
+
+	case intField:
+		if f.narrow {
+			limit := int64(1) << (f.bits - 1)
+			fmt.Fprintf(b, "if x := int64(%s); (x < %d) || (x >= %d) {\n", expr, -limit, limit)
+			fmt.Fprintf(b, "return nil, fmt.Errorf(%s, x, toyfloat.ErrValueOutOfRange)\n}\n",
+				strconv.Quote("field "+f.name+" = %d: %w"))
+		}
+		fmt.Fprintf(b, "o = %sPut(data, o, uint64(%s), %d)\n", g.prefix, expr, f.bits)
+
+	case uintField:
+		if f.narrow {
+			fmt.Fprintf(b, "if x := uint64(%s); 0 != x>>%d {\n", expr, f.bits)
+			fmt.Fprintf(b, "return nil, fmt.Errorf(%s, x, toyfloat.ErrValueOutOfRange)\n}\n",
+				strconv.Quote("field "+f.name+" = %d: %w"))
+		}
+		fmt.Fprintf(b, "o = %sPut(data, o, uint64(%s), %d)\n", g.prefix, expr, f.bits)
+
+	case boolField:
+		fmt.Fprintf(b, "o = %sPut(data, o, %sBit(%s), 1)\n",
+			g.prefix, g.prefix, convert("bool", f.goType, expr))
+
+	case structField:
+		for i := range f.fields {
+			g.writePut(b, &f.fields[i], expr+f.fields[i].path, depth)
+		}
+
+	case arrayField:
+		index := fmt.Sprintf("i%d", depth)
+		fmt.Fprintf(b, "for %s := range %s {\n", index, expr)
+		g.writePut(b, &f.fields[0], expr+"["+index+"]", depth+1)
+		fmt.Fprintf(b, "}\n")
+	}
+}
+
+// convert converts the expression, if the types differ.
+func convert(to, from, expr string) string {
+	if to == from {
+		return expr
+	}
+	return fmt.Sprintf("%s(%s)", to, expr)
+}
+
+func (g *generator) writeUnmarshal(b *bytes.Buffer, plan *structPlan) {
+	fmt.Fprintf(b, "// UnmarshalToyfloat is MarshalToyfloat in reverse,"+
+		" the same as toyfloat.Unmarshal.\n")
+	fmt.Fprintf(b, "func (v *%s) UnmarshalToyfloat(data []byte) error {\n", plan.name)
+	fmt.Fprintf(b, "if len(data) != %d {\n", (plan.bits+7)/8)
+	fmt.Fprintf(b, "return fmt.Errorf(%s, len(data), toyfloat.ErrRecordLength)\n}\n\n",
+		strconv.Quote("%d bytes for "+g.pkg+"."+plan.name+": %w"))
+	if plan.bits > 0 {
+		fmt.Fprintf(b, "var g uint64\n")
+		fmt.Fprintf(b, "o := uint(0)\n\n")
+		g.writeGet(b, &plan.root, "v", 0)
+	}
+	fmt.Fprintf(b, "return nil\n}\n\n")
+}
+
+func (g *generator) writeGet(b *bytes.Buffer, f *field, expr string, depth int) {
+	if (structField != f.kind) && (arrayField != f.kind) {
+		fmt.Fprintf(b, "g, o = %sGet(data, o, %d)\n", g.prefix, f.bits)
+	}
+
+	switch f.kind {
+	case floatField:
+		tf := g.types[f.tf]
+		align := 0
+		if tf.Layout().AlignMSB {
+			align = 16 - f.bits
+		}
+
+		code := "uint16(g)"
+		if 0 != align {
+			code = fmt.Sprintf("uint16(g) << %d", align)
+		}
+
+		decoded := fmt.Sprintf("%s(%s)", g.decoder(f.tf), code)
+		fmt.Fprintf(b, "%s = %s\n", expr, convert(f.goType, "float64", decoded))
+
+	case intField:
+		if f.bits < 64 {
+			shift := 64 - f.bits
+			fmt.Fprintf(b, "%s = %s(int64(g<<%d) >> %d)\n", expr, f.goType, shift, shift)
+		} else {
+			fmt.Fprintf(b, "%s = %s(int64(g))\n", expr, f.goType)
+		}
+
+	case uintField:
+		fmt.Fprintf(b, "%s = %s(g)\n", expr, f.goType)
+
+	case boolField:
+		fmt.Fprintf(b, "%s = %s\n", expr, convert(f.goType, "bool", "0 != g"))
+
+	case structField:
+		for i := range f.fields {
+			g.writeGet(b, &f.fields[i], expr+f.fields[i].path, depth)
+		}
+
+	case arrayField:
+		index := fmt.Sprintf("i%d", depth)
+		fmt.Fprintf(b, "for %s := range %s {\n", index, expr)
+		g.writeGet(b, &f.fields[0], expr+"["+index+"]", depth+1)
+		fmt.Fprintf(b, "}\n")
+	}
+}
+
+func (g *generator) writeHelpers(b *bytes.Buffer) {
+	p := g.prefix
+
+	fmt.Fprintf(b, "// %sPut writes n bits of x at the offset,"+
+		" from the most-significant one.\n", p)
+	fmt.Fprintf(b, "func %sPut(data []byte, offset uint, x uint64, n uint) uint {\n", p)
+	fmt.Fprintf(b, "for n > 0 {\n")
+	fmt.Fprintf(b, "free := 8 - offset%%8\n")
+	fmt.Fprintf(b, "take := n\n")
+	fmt.Fprintf(b, "if take > free {\n take = free\n}\n\n")
+	fmt.Fprintf(b, "chunk := byte(x>>(n-take)) & (byte(1)<<take - 1)\n")
+	fmt.Fprintf(b, "data[offset/8] |= chunk << (free - take)\n")
+	fmt.Fprintf(b, "offset += take\n")
+	fmt.Fprintf(b, "n -= take\n")
+	fmt.Fprintf(b, "}\n")
+	fmt.Fprintf(b, "return offset\n}\n\n")
+
+	fmt.Fprintf(b, "// %sGet is %sPut in reverse.\n", p, p)
+	fmt.Fprintf(b, "func %sGet(data []byte, offset uint, n uint) (uint64, uint) {\n", p)
+	fmt.Fprintf(b, "x := uint64(0)\n")
+	fmt.Fprintf(b, "for n > 0 {\n")
+	fmt.Fprintf(b, "free := 8 - offset%%8\n")
+	fmt.Fprintf(b, "take := n\n")
+	fmt.Fprintf(b, "if take > free {\n take = free\n}\n\n")
+	fmt.Fprintf(b, "chunk := (data[offset/8] >> (free - take)) & (byte(1)<<take - 1)\n")
+	fmt.Fprintf(b, "x = x<<take | uint64(chunk)\n")
+	fmt.Fprintf(b, "offset += take\n")
+	fmt.Fprintf(b, "n -= take\n")
+	fmt.Fprintf(b, "}\n")
+	fmt.Fprintf(b, "return x, offset\n}\n\n")
+
+	if g.bools {
+		fmt.Fprintf(b, "func %sBit(b bool) uint64 {\n", p)
+		fmt.Fprintf(b, "if b {\n return 1\n}\n")
+		fmt.Fprintf(b, "return 0\n}\n\n")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/georgy7/toyfloat"
+)
+
+func TestGeneratedExample(t *testing.T) {
+	dir := filepath.Join("internal", "example")
+	expected, err := ioutil.ReadFile(filepath.Join(dir, "point_toyfloat.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := generateStructs(dir, []string{"Point", "Track"})
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(source, expected) {
+		t.Fatalf("point_toyfloat.go is outdated, run go generate")
+	}
+}
+
+func TestStructErrors(t *testing.T) {
+	cases := map[string]error{
+		"A float64":                      toyfloat.ErrInvalidTag,
+		"A float64 `toyfloat:\"x5,12\"`": toyfloat.ErrInvalidTag,
+		"A int":                          toyfloat.ErrInvalidTag,
+		"A uint8 `toyfloat:\"9\"`":       toyfloat.ErrInvalidTag,
+		"A []float64":                    toyfloat.ErrUnsupportedField,
+		"A *int8":                        toyfloat.ErrUnsupportedField,
+		"A float64 `toyfloat:\"x4,17\"`": toyfloat.ErrLengthTooLarge,
+	}
+
+	for field, expected := range cases {
+		dir, err := ioutil.TempDir("", "toyfloatgen")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		source := "package bad\n\ntype T struct {\n" + field + "\n}\n"
+		err = ioutil.WriteFile(filepath.Join(dir, "bad.go"), []byte(source), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := generateStructs(dir, []string{"T"}); !errors.Is(err, expected) {
+			t.Fatalf("%s: %v expected, got %v", field, expected, err)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+
+	"github.com/georgy7/toyfloat"
+)
+
+// generateType returns the source of a file with EncodeName and DecodeName.
+// The spec is anything toyfloat.ParseTag reads.
+func generateType(spec, name, pkg string) ([]byte, error) {
+	tf, err := toyfloat.ParseTag(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by toyfloatgen -spec %s -name %s; DO NOT EDIT.\n\n",
+		spec, name)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	fmt.Fprintf(&b, "import \"math\"\n\n")
+
+	if err := toyfloat.WriteGo(&b, tf, "Encode"+name, "Decode"+name); err != nil {
+		return nil, err
+	}
+	return format.Source(b.Bytes())
+}
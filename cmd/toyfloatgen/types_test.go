@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestGeneratedSpecialized(t *testing.T) {
+	files := []struct {
+		spec, name, file string
+	}{
+		{"12", "X12", "x12_toyfloat.go"},
+		{"s10x3b2m-6+nonfinite+zero=marker+twos+layout=sxm.msb+protect=crc4",
+			"Twos10", "twos10_toyfloat.go"},
+	}
+
+	for _, f := range files {
+		path := filepath.Join("internal", "specialized", f.file)
+		expected, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		source, err := generateType(f.spec, f.name, "specialized")
+		if err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(source, expected) {
+			t.Fatalf("%s is outdated, run go generate", f.file)
+		}
+	}
+
+	if _, err := generateType("x5,12", "X", "p"); err == nil {
+		t.Fatalf("error expected")
+	}
+}
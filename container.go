@@ -0,0 +1,455 @@
+package toyfloat
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// The container file format keeps codes of one type
+// with the type itself, so files can be exchanged.
+// All numbers are big-endian.
+//
+//	offset  size  field
+//	0       4     magic "TFLT"
+//	4       1     version, 1
+//	5       1     flags, bit 0 is set for the delta-coded payload
+//	6       2     length of the spec
+//	8       n     spec of the type, as Type.Spec returns it
+//	8+n     8     number of codes
+//	16+n    ...   payload
+//	end-4   4     CRC-32 (IEEE) of all the bytes before it
+//
+// The codes are stored in the comparable form (see ToComparable),
+// which takes the length of the type in bits,
+// or 16 bits, if the type keeps tags (TagsPreserved).
+//
+// The raw payload is the comparable forms packed from the most-significant
+// bit of the first byte, with zeros in the unused bits of the last byte.
+//
+// The delta-coded payload is the differences between the comparable forms,
+// starting from zero, as signed varints (see binary.PutVarint).
+// Slowly changing values take a byte per code.
+//
+// The count comes first, so that both sides stream the payload:
+// the writer is given the count, and the reader checks
+// the checksum, when it reaches the end. A count at the end
+// would not do: the unused bits of the raw payload can hold
+// whole codes of types shorter than 8 bits, so the reader
+// could not tell where the codes end, before it reads the count.
+// Counts above the largest int are malformed.
+const (
+	fileMagic   = "TFLT"
+	fileVersion = 1
+
+	fileDeltaFlag = 0x1
+)
+
+// FileWriter writes a container file of the codes of a type.
+// The header is written on the first Write or on Close,
+// and the payload as the codes come, through a buffer.
+//
+// The extra bits of the codes are not stored, unless the type keeps tags.
+// Check bits of types made WithProtection are computed anew
+// on reading, so Verify the codes before writing them.
+type FileWriter struct {
+	// Delta selects the delta-coded payload.
+	// It must be set before the first Write.
+	Delta bool
+
+	w       io.Writer
+	out     *bufio.Writer
+	crc     hash.Hash32
+	t       Type
+	count   uint64
+	written uint64
+	last    int
+	bits    uint64 // not written yet
+	size    uint8  // of the bits
+	closed  bool
+}
+
+// NewFileWriter makes a writer of count codes of the type.
+// The count is written in the header, so it must be known
+// before the codes, which are written as they come.
+// Types with too many reserved codes have specs longer than
+// the header can hold, and result in ErrMalformedFile.
+func NewFileWriter(w io.Writer, t Type, count int) (*FileWriter, error) {
+	if !t.IsValid() {
+		return nil, ErrInvalidType
+	} else if count < 0 {
+		return nil, ErrFileCount
+	} else if len(t.Spec()) > 0xFFFF {
+		return nil, ErrMalformedFile
+	}
+	return &FileWriter{w: w, t: t, count: uint64(count)}, nil
+}
+
+// Write adds codes of the type. It returns ErrFileCount
+// for codes beyond the count, and does not write them.
+func (fw *FileWriter) Write(codes ...uint16) error {
+	if fw.closed {
+		return ErrFileClosed
+	} else if uint64(len(codes)) > fw.count-fw.written {
+		return ErrFileCount
+	}
+
+	for _, code := range codes {
+		if err := fw.write(code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteValues adds the codes of the values.
+func (fw *FileWriter) WriteValues(values ...float64) error {
+	if fw.closed {
+		return ErrFileClosed
+	} else if uint64(len(values)) > fw.count-fw.written {
+		return ErrFileCount
+	}
+
+	for _, v := range values {
+		if err := fw.write(encode(v, &fw.t)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close writes the rest of the file, and returns ErrFileCount,
+// if it has fewer codes than the count.
+// It does not close the underlying writer.
+func (fw *FileWriter) Close() error {
+	if fw.closed {
+		return ErrFileClosed
+	}
+	fw.closed = true
+
+	if fw.written != fw.count {
+		return ErrFileCount
+	} else if err := fw.start(); err != nil {
+		return err
+	}
+
+	if fw.size > 0 {
+		// Zeros in the unused bits.
+		if err := fw.out.WriteByte(byte(fw.bits << (8 - fw.size))); err != nil {
+			return err
+		}
+	}
+
+	if err := fw.out.Flush(); err != nil {
+		return err
+	}
+
+	var checksum [4]byte
+	binary.BigEndian.PutUint32(checksum[:], fw.crc.Sum32())
+	_, err := fw.w.Write(checksum[:])
+	return err
+}
+
+// start writes the header once.
+func (fw *FileWriter) start() error {
+	if nil != fw.out {
+		return nil
+	}
+
+	fw.crc = crc32.NewIEEE()
+	fw.out = bufio.NewWriter(io.MultiWriter(fw.w, fw.crc))
+
+	spec := fw.t.Spec()
+	header := make([]byte, 0, 8+len(spec)+8)
+	header = append(header, fileMagic...)
+	header = append(header, fileVersion, 0)
+	if fw.Delta {
+		header[5] = fileDeltaFlag
+	}
+
+	header = append(header, byte(len(spec)>>8), byte(len(spec)))
+	header = append(header, spec...)
+
+	var count [8]byte
+	binary.BigEndian.PutUint64(count[:], fw.count)
+	header = append(header, count[:]...)
+
+	_, err := fw.out.Write(header)
+	return err
+}
+
+func (fw *FileWriter) write(code uint16) error {
+	if err := fw.start(); err != nil {
+		return err
+	}
+	fw.written++
+
+	c := int(fw.t.ToComparable(code))
+	if fw.Delta {
+		var number [binary.MaxVarintLen64]byte
+		n := binary.PutVarint(number[:], int64(c-fw.last))
+		fw.last = c
+
+		_, err := fw.out.Write(number[:n])
+		return err
+	}
+
+	// From the most-significant bit.
+	fw.bits = fw.bits<<fileWidth(&fw.t) | uint64(c)
+	fw.size += fileWidth(&fw.t)
+	for fw.size >= 8 {
+		fw.size -= 8
+		if err := fw.out.WriteByte(byte(fw.bits >> fw.size)); err != nil {
+			return err
+		}
+	}
+	fw.bits &= (uint64(1) << fw.size) - 1
+	return nil
+}
+
+// FileReader reads a container file written by FileWriter.
+type FileReader struct {
+	t         Type
+	delta     bool
+	in        fileInput
+	count     uint64
+	remaining uint64
+	last      int64
+	bits      uint64 // not read yet
+	size      uint8  // of the bits
+	finished  bool
+	err       error
+}
+
+// OpenFileReader reads the header of the file,
+// and makes its type from the spec in it.
+// It returns ErrNotToyfloatFile, ErrFileVersion
+// or ErrMalformedFile for files it cannot read, and errors
+// of ParseType for unknown types.
+// The payload is read by Read and ReadValues.
+func OpenFileReader(r io.Reader) (*FileReader, error) {
+	fr := &FileReader{in: fileInput{r: bufio.NewReader(r)}}
+
+	var header [8]byte
+	if err := fr.in.read(header[:len(fileMagic)]); err != nil {
+		if nil != fr.in.err {
+			return nil, err
+		}
+		return nil, ErrNotToyfloatFile
+	} else if string(header[:len(fileMagic)]) != fileMagic {
+		return nil, ErrNotToyfloatFile
+	} else if err := fr.in.read(header[len(fileMagic):]); err != nil {
+		return nil, err
+	} else if fileVersion != header[4] {
+		return nil, ErrFileVersion
+	}
+
+	flags := header[5]
+	if 0 != flags&^fileDeltaFlag {
+		return nil, ErrMalformedFile
+	}
+
+	spec := make([]byte, binary.BigEndian.Uint16(header[6:]))
+	if err := fr.in.read(spec); err != nil {
+		return nil, err
+	}
+
+	t, err := ParseType(string(spec))
+	if err != nil {
+		return nil, err
+	}
+
+	var count [8]byte
+	if err := fr.in.read(count[:]); err != nil {
+		return nil, err
+	}
+
+	fr.t = t
+	fr.delta = 0 != flags&fileDeltaFlag
+	fr.count = binary.BigEndian.Uint64(count[:])
+	if fr.count > uint64(^uint(0)>>1) {
+		return nil, ErrMalformedFile
+	}
+	fr.remaining = fr.count
+	return fr, nil
+}
+
+// Type returns the type of the codes.
+func (fr *FileReader) Type() Type {
+	return fr.t
+}
+
+// Delta tells, if the payload is delta-coded.
+func (fr *FileReader) Delta() bool {
+	return fr.delta
+}
+
+// Len returns the number of codes in the file, as the header says.
+func (fr *FileReader) Len() int {
+	return int(fr.count)
+}
+
+// Read reads up to len(codes) codes. With the last code,
+// it checks the end of the file, which may result in ErrFileChecksum
+// or ErrMalformedFile. After that, it returns 0 and io.EOF.
+// Errors are returned again by the later calls.
+func (fr *FileReader) Read(codes []uint16) (int, error) {
+	return fr.read(len(codes), func(i int, code uint16) {
+		codes[i] = code
+	})
+}
+
+// ReadValues is Read, that decodes the codes.
+func (fr *FileReader) ReadValues(values []float64) (int, error) {
+	return fr.read(len(values), func(i int, code uint16) {
+		values[i] = decode(code, &fr.t)
+	})
+}
+
+// ----------------
+
+// fileInput counts the checksum of the bytes it reads.
+// It turns the unexpected end of the file into ErrMalformedFile,
+// and keeps other errors of the reader.
+type fileInput struct {
+	r   *bufio.Reader
+	sum uint32
+	one [1]byte
+	err error
+}
+
+func (in *fileInput) ReadByte() (byte, error) {
+	b, err := in.r.ReadByte()
+	if err != nil {
+		return 0, in.fail(err)
+	}
+
+	in.one[0] = b
+	in.sum = crc32.Update(in.sum, crc32.IEEETable, in.one[:])
+	return b, nil
+}
+
+func (in *fileInput) read(p []byte) error {
+	if _, err := io.ReadFull(in.r, p); err != nil {
+		return in.fail(err)
+	}
+	in.sum = crc32.Update(in.sum, crc32.IEEETable, p)
+	return nil
+}
+
+func (in *fileInput) fail(err error) error {
+	if (io.EOF != err) && (io.ErrUnexpectedEOF != err) {
+		in.err = err
+		return err
+	}
+	return ErrMalformedFile
+}
+
+// fileWidth returns the number of bits of the comparable form.
+func fileWidth(t *Type) uint8 {
+	if TagsPreserved == t.tags {
+		return 16
+	}
+	return t.length
+}
+
+// fileCode is FromComparable, that returns codes the way Encode does.
+func fileCode(c uint16, t *Type) uint16 {
+	code := comparableToCode(c&t.bitmask, t)
+	if !t.twos && !t.rearranged {
+		code &= t.bitmask
+	}
+
+	if TagsPreserved == t.tags {
+		return t.WithTag(code, c>>t.length)
+	} else if NoProtection != t.protection {
+		return t.Protect(code)
+	}
+	return code
+}
+
+func (fr *FileReader) read(n int, put func(i int, code uint16)) (int, error) {
+	if nil != fr.err {
+		return 0, fr.err
+	}
+
+	i := 0
+	for ; (i < n) && (fr.remaining > 0); i++ {
+		c, err := fr.next()
+		if err != nil {
+			fr.err = err
+			return i, err
+		}
+		put(i, fileCode(c, &fr.t))
+		fr.remaining--
+	}
+
+	if (0 == fr.remaining) && !fr.finished {
+		fr.finished = true
+		if err := fr.finish(); err != nil {
+			fr.err = err
+			return i, err
+		}
+	}
+
+	if (0 == i) && (n > 0) {
+		fr.err = io.EOF
+		return 0, io.EOF
+	}
+	return i, nil
+}
+
+// next returns the next comparable form.
+func (fr *FileReader) next() (uint16, error) {
+	width := fileWidth(&fr.t)
+
+	if fr.delta {
+		delta, err := binary.ReadVarint(&fr.in)
+		if err != nil {
+			if nil != fr.in.err {
+				return 0, err
+			}
+			return 0, ErrMalformedFile
+		}
+
+		c := fr.last + delta
+		if (c < 0) || (c > int64(1)<<width-1) {
+			return 0, ErrMalformedFile
+		}
+		fr.last = c
+		return uint16(c), nil
+	}
+
+	for fr.size < width {
+		b, err := fr.in.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		fr.bits = fr.bits<<8 | uint64(b)
+		fr.size += 8
+	}
+
+	fr.size -= width
+	c := uint16(fr.bits >> fr.size)
+	fr.bits &= (uint64(1) << fr.size) - 1
+	return c, nil
+}
+
+// finish checks the checksum, and that nothing follows it.
+func (fr *FileReader) finish() error {
+	var checksum [4]byte
+	if _, err := io.ReadFull(fr.in.r, checksum[:]); err != nil {
+		return fr.in.fail(err)
+	} else if binary.BigEndian.Uint32(checksum[:]) != fr.in.sum {
+		return ErrFileChecksum
+	}
+
+	if _, err := fr.in.r.ReadByte(); nil == err {
+		return ErrMalformedFile
+	} else if io.EOF != err {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+package toyfloat
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestFileRoundTrip(t *testing.T) {
+	specs := []string{
+		"s12x4b2m-8",
+		"u5x3b2m-6",
+		"s16x2b3m-3+nonfinite+zero=canonical",
+		"s10x3b2m-6+nonfinite+zero=marker+twos+layout=sxm.msb+protect=crc4",
+		"s9x2b3m-3+reserved=1.2+layout=xms+protect=hamming",
+		"s11x3b2m-6+twos",
+		"s12x4b2m-8+tags=keep",
+	}
+
+	values := []float64{math.NaN(), math.Inf(+1), math.Inf(-1), math.Copysign(0, -1)}
+	for v := -40.0; v < 40; v += 0.37 {
+		values = append(values, v, v*1e-3)
+	}
+
+	for _, spec := range specs {
+		tf, err := ParseType(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		codes := make([]uint16, len(values))
+		for i, v := range values {
+			codes[i] = tf.Encode(v)
+		}
+		if TagsPreserved == tf.tags {
+			for i := range codes {
+				codes[i] = tf.WithTag(codes[i], uint16(i))
+			}
+		}
+
+		for _, delta := range []bool{false, true} {
+			var b bytes.Buffer
+			fw, err := NewFileWriter(&b, tf, len(codes))
+			if err != nil {
+				t.Fatal(err)
+			}
+			fw.Delta = delta
+
+			if err := fw.Write(codes[:10]...); err != nil {
+				t.Fatal(err)
+			} else if err := fw.Write(codes[10:]...); err != nil {
+				t.Fatal(err)
+			} else if err := fw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			fr, err := OpenFileReader(&b)
+			if err != nil {
+				t.Fatalf("%s, %v: %v", spec, delta, err)
+			} else if fr.Type() != tf || fr.Delta() != delta || fr.Len() != len(codes) {
+				t.Fatalf("%s, %v: %s, %d", spec, delta, fr.t.Spec(), fr.Len())
+			}
+
+			back := make([]uint16, len(codes)+1)
+			if n, err := fr.Read(back[:7]); n != 7 || err != nil {
+				t.Fatalf("%d, %v", n, err)
+			} else if n, err := fr.Read(back[7:]); n != len(codes)-7 || err != nil {
+				t.Fatalf("%d, %v", n, err)
+			} else if _, err := fr.Read(back); err != io.EOF {
+				t.Fatalf("io.EOF expected, got %v", err)
+			}
+
+			for i, code := range codes {
+				if back[i] != code {
+					t.Fatalf("%s, %v: %v: 0x%X != 0x%X", spec, delta, values[i], back[i], code)
+				}
+			}
+		}
+	}
+}
+
+func TestFileLayout(t *testing.T) {
+	tf := makeTypeX4(12, true, t)
+
+	var b bytes.Buffer
+	fw, err := NewFileWriter(&b, tf, 3)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := fw.WriteValues(1, -1, 0); err != nil {
+		t.Fatal(err)
+	} else if err := fw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := b.Bytes()
+	spec := "s12x4b2m-8"
+
+	// 3 codes of 12 bits are 5 bytes.
+	if len(data) != 8+len(spec)+8+5+4 {
+		t.Fatalf("%d bytes", len(data))
+	} else if string(data[:6]) != "TFLT\x01\x00" ||
+		binary.BigEndian.Uint16(data[6:]) != uint16(len(spec)) ||
+		string(data[8:8+len(spec)]) != spec ||
+		binary.BigEndian.Uint64(data[8+len(spec):]) != 3 {
+
+		t.Fatalf("%q", data)
+	}
+
+	c := tf.ToComparable(tf.Encode(1))
+	payload := data[16+len(spec):]
+	if uint16(payload[0])<<4|uint16(payload[1])>>4 != c {
+		t.Fatalf("%X: 0x%X", payload, c)
+	}
+
+	fr, err := OpenFileReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := make([]float64, 2)
+	if n, err := fr.ReadValues(values); n != 2 || err != nil || values[0] != 1 || values[1] != -1 {
+		t.Fatalf("%d, %v: %v", n, err, values)
+	} else if n, err := fr.ReadValues(values); n != 1 || err != nil || values[0] != 0 {
+		t.Fatalf("%d, %v: %v", n, err, values)
+	} else if _, err := fr.ReadValues(values); err != io.EOF {
+		t.Fatalf("io.EOF expected, got %v", err)
+	}
+
+	if err := fw.Write(1); err != ErrFileClosed {
+		t.Fatalf("ErrFileClosed expected, got %v", err)
+	} else if err := fw.Close(); err != ErrFileClosed {
+		t.Fatalf("ErrFileClosed expected, got %v", err)
+	} else if _, err := NewFileWriter(&b, Type{}, 0); err != ErrInvalidType {
+		t.Fatalf("ErrInvalidType expected, got %v", err)
+	}
+}
+
+func TestFileCount(t *testing.T) {
+	tf := makeTypeX4(12, true, t)
+
+	var b bytes.Buffer
+	if _, err := NewFileWriter(&b, tf, -1); err != ErrFileCount {
+		t.Fatalf("ErrFileCount expected, got %v", err)
+	}
+
+	fw, _ := NewFileWriter(&b, tf, 2)
+	if err := fw.Write(1, 2, 3); err != ErrFileCount {
+		t.Fatalf("ErrFileCount expected, got %v", err)
+	} else if err := fw.Write(1); err != nil {
+		t.Fatal(err)
+	} else if err := fw.WriteValues(1, 2); err != ErrFileCount {
+		t.Fatalf("ErrFileCount expected, got %v", err)
+	} else if err := fw.Close(); err != ErrFileCount {
+		t.Fatalf("ErrFileCount expected, got %v", err)
+	}
+
+	// An empty file.
+	b.Reset()
+	fw, _ = NewFileWriter(&b, tf, 0)
+	if err := fw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fr, err := OpenFileReader(&b)
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := fr.Read(make([]uint16, 1)); err != io.EOF {
+		t.Fatalf("io.EOF expected, got %v", err)
+	}
+}
+
+func TestFileErrors(t *testing.T) {
+	tf := makeTypeX4(12, true, t)
+
+	var b bytes.Buffer
+	fw, _ := NewFileWriter(&b, tf, 4)
+	fw.Delta = true
+	if err := fw.WriteValues(1, 2, 3, -4); err != nil {
+		t.Fatal(err)
+	} else if err := fw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	good := b.Bytes()
+
+	// resign changes the file without the checksum, and adds a new one.
+	resign := func(change func(data []byte) []byte) []byte {
+		data := change(append([]byte(nil), good[:len(good)-4]...))
+		return append(data, 0, 0, 0, 0)
+	}
+	sign := func(data []byte) []byte {
+		binary.BigEndian.PutUint32(data[len(data)-4:], crc32.ChecksumIEEE(data[:len(data)-4]))
+		return data
+	}
+
+	// The varints of the deltas follow the count: 80 30, 80 02, 7E and FD 26.
+	payload := 8 + len(tf.Spec()) + 8
+	cases := []struct {
+		data []byte
+		err  error
+	}{
+		{nil, ErrNotToyfloatFile},
+		{[]byte("TFLX\x01"), ErrNotToyfloatFile},
+		{good[:10], ErrMalformedFile},
+		{good[:payload+2], ErrMalformedFile},
+		{append(append([]byte(nil), good...), 0), ErrMalformedFile},
+		{resign(func(d []byte) []byte { d[payload+3] = 4; return d }), ErrFileChecksum},
+		{sign(resign(func(d []byte) []byte { d[4] = 2; return d })), ErrFileVersion},
+		{sign(resign(func(d []byte) []byte { d[5] = 2; return d })), ErrMalformedFile},
+		{sign(resign(func(d []byte) []byte { d[payload] = 5; return d })), ErrMalformedFile},
+		{sign(resign(func(d []byte) []byte { d[len(d)-1] = 0x7F; return d })), ErrMalformedFile},
+	}
+
+	for i, c := range cases {
+		if err := readFile(c.data); err != c.err {
+			t.Fatalf("%d: %v expected, got %v", i, c.err, err)
+		}
+	}
+
+	// Errors stay.
+	fr, err := OpenFileReader(bytes.NewReader(good[:payload+2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes := make([]uint16, 4)
+	if n, err := fr.Read(codes); n != 1 || err != ErrMalformedFile {
+		t.Fatalf("%d, %v", n, err)
+	} else if n, err := fr.Read(codes); n != 0 || err != ErrMalformedFile {
+		t.Fatalf("%d, %v", n, err)
+	}
+
+	// The payload is read as needed.
+	failure := errors.New("no more data")
+	r := io.MultiReader(bytes.NewReader(good[:payload+2]), &failingReader{failure})
+	if fr, err := OpenFileReader(r); err != nil {
+		t.Fatal(err)
+	} else if n, err := fr.Read(codes); n != 1 || err != failure {
+		t.Fatalf("%d, %v", n, err)
+	}
+
+	// A type with only negative exponents.
+	spec := "u8x2b2m-100"
+	header := []byte{'T', 'F', 'L', 'T', fileVersion, 0, 0, byte(len(spec))}
+	header = append(append(header, spec...), 0, 0, 0, 0, 0, 0, 0, 1, 0x55, 0, 0, 0, 0)
+	if err := readFile(sign(header)); err != nil {
+		t.Fatal(err)
+	} else if fr, err := OpenFileReader(bytes.NewReader(sign(header))); err != nil {
+		t.Fatal(err)
+	} else if codes := make([]uint16, 1); fr.t.Spec() != spec {
+		t.Fatal(fr.t.Spec())
+	} else if n, err := fr.Read(codes); n != 1 || err != nil || codes[0] != 0x55 {
+		t.Fatalf("%d, %v: 0x%X", n, err, codes[0])
+	}
+
+	// A scale out of the range of float64.
+	spec = "u8x2b2m-9223372036854775808"
+	header = []byte{'T', 'F', 'L', 'T', fileVersion, 0, 0, byte(len(spec))}
+	header = append(append(header, spec...), 0, 0, 0, 0, 0, 0, 0, 1, 0x55, 0, 0, 0, 0)
+	if err := readFile(sign(header)); !errors.Is(err, ErrMinExponentTooSmall) {
+		t.Fatalf("ErrMinExponentTooSmall expected, got %v", err)
+	}
+
+	// Counts above the largest int.
+	for _, count := range []uint64{1 << 63, ^uint64(0)} {
+		data := append([]byte(nil), good[:payload-8]...)
+		data = append(data, make([]byte, 8)...)
+		binary.BigEndian.PutUint64(data[payload-8:], count)
+		if _, err := OpenFileReader(bytes.NewReader(data)); err != ErrMalformedFile {
+			t.Fatalf("0x%X: ErrMalformedFile expected, got %v", count, err)
+		}
+	}
+
+	// An unknown type.
+	bad := sign(resign(func(d []byte) []byte { d[8] = 'x'; return d }))
+	if _, err := OpenFileReader(bytes.NewReader(bad)); err == nil {
+		t.Fatal("error expected")
+	}
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
+// readFile returns the first error of reading the whole file.
+func readFile(data []byte) error {
+	fr, err := OpenFileReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	codes := make([]uint16, 3)
+	for {
+		if _, err := fr.Read(codes); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func BenchmarkReadDeltaFile(b *testing.B) {
+	tf, _ := NewTypeX4(12, true)
+
+	var buffer bytes.Buffer
+	fw, _ := NewFileWriter(&buffer, tf, 1024)
+	fw.Delta = true
+	for i := 0; i < 1024; i++ {
+		_ = fw.WriteValues(math.Sin(float64(i) * 0.01))
+	}
+	_ = fw.Close()
+	data := buffer.Bytes()
+
+	codes := make([]uint16, 256)
+	r := 0
+	for i := 0; i < b.N; i++ {
+		fr, _ := OpenFileReader(bytes.NewReader(data))
+		for n, err := fr.Read(codes); err == nil; n, err = fr.Read(codes) {
+			r += n
+		}
+	}
+	intResult = r
+}
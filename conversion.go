@@ -1,37 +1,62 @@
 // Package toyfloat provides tiny (3 to 16 bits)
 // floating-point number formats for serialization.
+//
+// The zero Type is invalid. It encodes any number to 0
+// and decodes any code to 0. Use IsValid or the Try... methods
+// to detect it.
+//
+// The package never writes to standard output or standard error.
+// It panics only on programming errors: a nil *Type, slices
+// of different lengths, where their lengths must match,
+// or an index of a Packer field that does not exist.
 package toyfloat
 
-import (
-	"errors"
-	"fmt"
-	"math"
-)
+import "math"
 
 // Type is a reusable immutable set of encoder settings.
+// Types are comparable: the ones made with the same arguments are equal,
+// so they can be used as map keys. The exception is a program, that makes
+// tens of megabytes of distinct types, such as from untrusted specs:
+// the types made after that are equal only to their copies.
 type Type struct {
-	mSize               uint8
+	length, xBase       uint8
+	xSize, mSize        uint8
+	minX                int
 	minus, mMask, xMask uint16
+	nonFinite           bool
+	zeroMode            NegativeZeroMode
+	twos                bool
+	layout              Layout
+	tags                TagPolicy
+	protection          Protection
+	checkSize           uint8
+	correctable         bool
+	rearranged          bool
+	align, sShift       uint8
+	xShift, mShift      uint8
+	maxMagnitude        uint16
+	infCode, nanCode    uint16
 	minValue, maxValue  float64
 	esFactor, dsFactor  float64
 	xBoundary           float64
-	scale               []float64
 	bitmask             uint16
+	plain               bool
+	data                *typeData
 }
 
 // NewTypeX2 makes a type with 2-bit exponent with default settings.
-func NewTypeX2(length int, signed bool) (Type, error) {
-	return NewType(uint8(length), 3, 2, -3, signed)
+func NewTypeX2(length int, signed bool, options ...Option) (Type, error) {
+	return NewType(uint8(length), 3, 2, -3, signed, options...)
 }
 
 // NewTypeX3 makes a type with 3-bit exponent with default settings.
-func NewTypeX3(length int, signed bool) (Type, error) {
-	return NewType(uint8(length), 2, 3, -6, signed)
+func NewTypeX3(length int, signed bool, options ...Option) (Type, error) {
+	return NewType(uint8(length), 2, 3, -6, signed, options...)
 }
 
 // NewTypeX4 makes a type with 4-bit exponent with default settings.
-func NewTypeX4(length int, signed bool) (Type, error) {
-	return NewType(uint8(length), 2, 4, -8, signed)
+func NewTypeX4(length int, signed bool, options ...Option) (Type, error) {
+	return NewType(uint8(length), 2, 4, -8, signed, options...)
 }
 
 // NewType allows creating custom types.
@@ -40,24 +65,28 @@ func NewTypeX4(length int, signed bool) (Type, error) {
 // The argument xSize is the number of bits that encode the power.
 // So the maximum power equals minX+(2^xSize)-1,
 // and the maximum exponential part equals xBase^(minX+(2^xSize)-1).
-func NewType(length, xBase, xSize uint8, minX int, signed bool) (Type, error) {
-	if (xBase < 2) || (xBase > 10) {
-		return Type{},
-			errors.New("only bases from 2 to 10 are supported")
+// Invalid arguments result in a *ParamError.
+func NewType(length, xBase, xSize uint8, minX int, signed bool,
+	options ...Option) (Type, error) {
+
+	p := Params{
+		Length: length,
+		XBase:  xBase,
+		XSize:  xSize,
+		MinX:   minX,
+		Signed: signed,
 	}
 
-	if minX >= 0 {
-		return Type{}, errors.New("c=1/(1-xBase^minX)" +
-			" where it is assumed that minX is not positive" +
-			" so that с makes sense")
+	for _, option := range options {
+		option(&p)
 	}
-
-	return newSettings(length, xBase, xSize, minX, signed)
+	return NewTypeFromParams(p)
 }
 
 // Encode converts a number to its binary representation for this type.
 // You cannot compare such values directly because they are "sign–magnitude".
 // Of course, they have zeros in extra most-significant bits.
+// Types made WithTwosComplement are the exception.
 func (t *Type) Encode(v float64) uint16 {
 	return encode(v, t)
 }
@@ -78,8 +107,27 @@ func (t *Type) UseIntegerDelta(last uint16, delta int) uint16 {
 
 // Abs returns encoded absolute value of encoded argument.
 // This does not work for the comparable form.
+// The marker of NegativeZeroMarker types and reserved codes
+// are returned as is. If the absolute value of a number falls
+// on a reserved code, Abs returns the nearest other code, as Encode does.
+// The tag is kept or cleared as WithTagPolicy says.
 func (t *Type) Abs(x uint16) uint16 {
-	return x & (^t.minus)
+	if ((NegativeZeroMarker == t.zeroMode) && t.IsNegativeZero(x)) || t.IsReserved(x) {
+		return applyTagPolicy(x, x, t)
+	}
+
+	var r uint16
+	if t.twos || t.rearranged {
+		r = toLayout(fromLayout(x, t)&(^t.minus), t)
+	} else {
+		r = x & (^t.minus)
+	}
+
+	if t.IsReserved(r) {
+		code := avoidReserved(fromLayout(r, t)&t.bitmask, math.Abs(t.Decode(x)), t)
+		r = toLayout(code, t)
+	}
+	return applyTagPolicy(r, x, t)
 }
 
 // ToComparable returns a representation close to "ones' complement",
@@ -87,28 +135,100 @@ func (t *Type) Abs(x uint16) uint16 {
 // Thus, all zeros mean the lowest value, and all ones mean the maximum.
 // Programming languages such as C, C++, Go define unsigned integer overflow,
 // which allows this form to be used for delta encoding without branching.
+//
+// With CanonicalZero and NegativeZeroMarker, negative numbers
+// are shifted by one, which makes it close to "two's complement".
+// See WithNegativeZero.
+//
+// For types made WithTwosComplement, it just inverts the sign bit.
+// Other layouts are converted to "s x m" first. See WithLayout.
+//
+// Extra bits are cleared, unless the tag is kept by WithTagPolicy.
+// Then it is placed above the comparable form, so tagged values
+// are ordered by the tag first.
 func (t *Type) ToComparable(tf uint16) uint16 {
+	c := codeToComparable(tf, t)
+	if TagsPreserved == t.tags {
+		return c | t.Tag(tf)<<t.length
+	}
+	return c
+}
+
+// FromComparable is ToComparable in reverse.
+// Note, that it does not reset extra bits (for performance reasons),
+// unless the type is made WithTagPolicy or WithProtection.
+// Comparable zero of CanonicalZero types, which ToComparable
+// never returns, is the lowest value, the same as one.
+func (t *Type) FromComparable(c uint16) uint16 {
+	code := comparableToCode(c, t)
+	if TagsPreserved == t.tags {
+		return t.WithTag(code, c>>t.length)
+	}
+	return applyTagPolicy(code, code, t)
+}
+
+// codeToComparable is ToComparable without tags.
+func codeToComparable(tf uint16, t *Type) uint16 {
+	if t.rearranged {
+		tf = fromPhysical(tf, t)
+	}
+
+	if t.twos {
+		return (tf ^ t.minus) & t.bitmask
+	}
+	return toComparable(tf, t)
+}
+
+// comparableToCode is FromComparable without tags.
+func comparableToCode(c uint16, t *Type) uint16 {
+	var r uint16
+	if t.twos {
+		r = signExtend(c^t.minus, t)
+	} else {
+		r = fromComparable(c, t)
+	}
+
+	if t.rearranged {
+		return toPhysical(r, t)
+	}
+	return r
+}
+
+// toComparable is ToComparable for sign–magnitude codes.
+func toComparable(tf uint16, t *Type) uint16 {
 	var r uint16
 	if 0 == tf&t.minus {
 		// It's true for both positive signed and unsigned numbers.
 		r = t.minus | tf
-	} else {
+	} else if KeepNegativeZero == t.zeroMode {
 		// Negative, including -0.
 		r = ^tf
+	} else if (NegativeZeroMarker == t.zeroMode) && (tf&t.bitmask == t.minus) {
+		return 0
+	} else {
+		// Negative, and -0 is the same as +0.
+		r = ^tf + 1
 	}
 	return r & t.bitmask
 }
 
-// FromComparable is ToComparable in reverse.
-// Note, that it does not reset extra bits (for performance reasons).
-func (t *Type) FromComparable(c uint16) uint16 {
+// fromComparable is FromComparable for sign–magnitude codes.
+func fromComparable(c uint16, t *Type) uint16 {
 	// Sign bit are inverted here, so it is
 	// not equal to its bitmask for a negative number.
 	// Also, variable "minus" equals zero for unsigned values,
 	// "0 != 0" is always false.
 	if t.minus != c&t.minus {
-		// Negative, including -0.
-		return ^c
+		if KeepNegativeZero == t.zeroMode {
+			// Negative, including -0.
+			return ^c
+		} else if 0 == c&t.bitmask {
+			if NegativeZeroMarker == t.zeroMode {
+				return t.minus
+			}
+			c++
+		}
+		return ^(c - 1)
 	}
 	return (^t.minus) & c
 }
@@ -127,39 +247,93 @@ func (t *Type) MaxValue() float64 {
 // ----------------
 // Implementation:
 
-func newSettings(length, xBase, xSize uint8, minX int, signed bool) (Type, error) {
+func newSettings(p Params) (Type, error) {
+	length, xBase, xSize, minX := p.Length, p.XBase, p.XSize, p.MinX
+
 	if length > 16 {
-		return Type{}, errors.New("maximum length is 16 bits")
+		return Type{}, ErrLengthTooLarge
 	}
 
 	signSize := uint8(0)
-	if signed {
+	if p.Signed {
 		signSize = 1
 	}
 
 	if length <= xSize+signSize {
-		return Type{}, errors.New("mantissa must be at least 1 bit wide")
+		return Type{}, ErrNoMantissa
 	}
 
 	mSize := length - (xSize + signSize)
 
+	// Both fields must fit into uint16 masks.
+	// The checks above make it so, and this one keeps it so.
 	if (xSize >= 16) || (mSize >= 16) {
-		return Type{}, errors.New("library Toyfloat is broken")
+		return Type{}, ErrLengthTooLarge
 	}
 
 	settings := Type{
-		mSize: mSize,
-		minus: uint16(0),
-		mMask: (uint16(1) << mSize) - 1,
-		xMask: (uint16(1) << xSize) - 1,
+		length: length,
+		xBase:  xBase,
+		xSize:  xSize,
+		mSize:  mSize,
+		minX:   minX,
+		minus:  uint16(0),
+		mMask:  (uint16(1) << mSize) - 1,
+		xMask:  (uint16(1) << xSize) - 1,
 	}
 
-	if signed {
+	if p.Signed {
 		settings.minus = uint16(1) << (length - 1)
 	}
 
-	settings.bitmask =
-		settings.minus | (settings.xMask << settings.mSize) | settings.mMask
+	if err := checkNegativeZero(p.NegativeZero, p.Signed); err != nil {
+		return Type{}, err
+	}
+	settings.zeroMode = p.NegativeZero
+
+	if p.TwosComplement {
+		if !p.Signed {
+			return Type{}, ErrTwosComplementUnsigned
+		}
+		settings.twos = true
+	}
+
+	if err := setLayout(&settings, p.Layout); err != nil {
+		return Type{}, err
+	}
+
+	if p.Tags > TagsPreserved {
+		return Type{}, ErrInvalidTagPolicy
+	} else if settings.twos && (0 == settings.align) && (TagsPreserved == p.Tags) {
+		// The extra bits are the sign extension.
+		return Type{}, ErrInvalidTagPolicy
+	}
+	settings.tags = p.Tags
+
+	if p.Protection > CRC4 {
+		return Type{}, ErrInvalidProtection
+	} else if err := setProtection(&settings, p.Protection); err != nil {
+		return Type{}, err
+	}
+
+	// The largest magnitude.
+	settings.infCode = (settings.xMask << settings.mSize) | settings.mMask
+	settings.bitmask = settings.minus | settings.infCode
+
+	settings.maxMagnitude = settings.infCode
+	if p.NonFinite {
+		if settings.infCode < 3 {
+			return Type{}, ErrTooFewCodes
+		}
+
+		settings.nonFinite = true
+		settings.nanCode = settings.infCode - 1
+		settings.maxMagnitude = settings.infCode - 2
+	}
+
+	if err := checkReserved(p.Reserved, &settings); err != nil {
+		return Type{}, err
+	}
 
 	// multiplier to encode the significand
 	settings.esFactor = powerOfTwo(mSize) / float64(xBase-1)
@@ -169,58 +343,102 @@ func newSettings(length, xBase, xSize uint8, minX int, signed bool) (Type, error
 	f64Base := float64(xBase)
 	settings.xBoundary = makeExponentBoundary(powerOfTwo(mSize), f64Base)
 
-	settings.scale = make([]float64, int(1)<<xSize)
-	maxX := minX + len(settings.scale) - 1
+	maxX := minX + (int(1) << xSize) - 1
 
+	// The maximum significand is almost xBase, hence +1.
 	maxF64BasePower := math.Log(math.MaxFloat64) / math.Log(float64(xBase))
 	if float64(maxX+1) > maxF64BasePower {
-		msg := fmt.Sprintf("b = %d, max power = %d (+1 for max m); "+
-			"limit for float64: %.0f\n", xBase, maxX, maxF64BasePower)
-		return Type{}, errors.New(msg)
+		return Type{}, ErrRangeExceedsFloat64
 	}
 
-	{
-		denominator := f64Base
-		for x := -1; x >= minX; x-- {
-			settings.scale[x-minX] = 1.0 / denominator
+	settings.data = internTypeData(&p, func() *typeData {
+		scale := make([]float64, int(1)<<xSize)
+
+		// All exponents may be negative, if -minX > 2^xSize.
+		top := -1
+		if maxX < top {
+			top = maxX
+		}
+		denominator := math.Pow(f64Base, float64(-top))
+		for x := top; x >= minX; x-- {
+			scale[x-minX] = 1.0 / denominator
 			denominator *= f64Base
 		}
 		for x := 0; x <= maxX; x++ {
-			settings.scale[x-minX] = math.Pow(f64Base, float64(x))
+			scale[x-minX] = math.Pow(f64Base, float64(x))
 		}
-	}
 
-	mMax := powerOfTwo(mSize) - 1.0
-	maxScale := get(settings.scale, settings.xMask)
+		return &typeData{
+			scale:    scale,
+			reserved: newReservedTable(p.Reserved, &settings),
+		}
+	})
+
+	mMax := float64(settings.maxMagnitude & settings.mMask)
+	maxScale := get(settings.data.scale, settings.maxMagnitude>>mSize)
 	internalMaximum := decodeSignificand(mMax, settings.dsFactor) * maxScale
 
-	a := settings.scale[0]
+	a := settings.data.scale[0]
 	c := 1.0 / (1.0 - a)
 
 	settings.maxValue = (internalMaximum - a) * c
 	settings.minValue = 0.0
-	if signed {
+	if p.Signed {
 		settings.minValue = -settings.maxValue
 	}
 
+	// Types without options take the short paths.
+	settings.plain = !p.NonFinite && (0 == len(p.Reserved)) &&
+		(KeepNegativeZero == p.NegativeZero) && !settings.twos && !settings.rearranged &&
+		(TagsIgnored == p.Tags) && (NoProtection == p.Protection)
+
 	return settings, nil
 }
 
 func encode(value float64, settings *Type) uint16 {
-	if math.IsNaN(value) {
+	if settings.plain {
+		return encodeNumber(value, settings)
+	}
+
+	code := encodeNumber(value, settings)
+	if (KeepNegativeZero != settings.zeroMode) && (code == settings.minus) {
+		// Small negative numbers.
+		code = 0x0
+	}
+	if (nil != settings.data) && (nil != settings.data.reserved) {
+		code = avoidReserved(code, value, settings)
+	}
+
+	code = toLayout(code, settings)
+	if NoProtection != settings.protection {
+		return settings.Protect(code)
+	}
+	return code
+}
+
+func encodeNumber(value float64, settings *Type) uint16 {
+	if nil == settings.data {
 		return 0x0
+	} else if math.IsNaN(value) {
+		// It is zero by default.
+		return settings.nanCode
 	} else if value > settings.maxValue {
-		return (settings.xMask << settings.mSize) | settings.mMask
+		if settings.nonFinite && math.IsInf(value, +1) {
+			return settings.infCode
+		}
+		return settings.maxMagnitude
 	} else if value < 0 {
 		if 0b0 == settings.minus {
 			return 0x0
 		} else if value < settings.minValue {
-			absValue := (settings.xMask << settings.mSize) | settings.mMask
-			return settings.minus | absValue
+			if settings.nonFinite && math.IsInf(value, -1) {
+				return settings.minus | settings.infCode
+			}
+			return settings.minus | settings.maxMagnitude
 		}
 	}
 
-	a := settings.scale[0]
+	a := settings.data.scale[0]
 	vReversedC := value * (1.0 - a)
 
 	if value < 0 {
@@ -230,10 +448,35 @@ func encode(value float64, settings *Type) uint16 {
 }
 
 func decode(tf uint16, s *Type) float64 {
-	a := s.scale[0]
+	if !s.plain {
+		if nil == s.data {
+			return 0.0
+		}
+
+		tf = fromLayout(tf, s)
+		if (nil != s.data.reserved) && s.data.reserved.is[tf&s.bitmask] {
+			return math.NaN()
+		} else if (KeepNegativeZero != s.zeroMode) && (tf&s.bitmask == s.minus) {
+			if NegativeZeroMarker == s.zeroMode {
+				return math.NaN()
+			}
+			return 0.0
+		} else if s.nonFinite {
+			if magnitude := tf & s.infCode; magnitude == s.infCode {
+				if isNegative(tf, s.minus) {
+					return math.Inf(-1)
+				}
+				return math.Inf(+1)
+			} else if magnitude == s.nanCode {
+				return math.NaN()
+			}
+		}
+	}
+
+	a := s.data.scale[0]
 	c := 1.0 / (1.0 - a)
 
-	scale := get(s.scale, (tf>>s.mSize)&(s.xMask))
+	scale := get(s.data.scale, (tf>>s.mSize)&(s.xMask))
 
 	significand := decodeSignificand(float64(tf&s.mMask), s.dsFactor)
 
@@ -250,6 +493,11 @@ func decode(tf uint16, s *Type) float64 {
 	return absValue
 }
 
+// decodeNumber is decode for sign–magnitude codes without extra bits.
+func decodeNumber(tf uint16, s *Type) float64 {
+	return decode(toLayout(tf, s), s)
+}
+
 func encodeInnerValue(inner float64, s *Type) uint16 {
 	binaryExponent, inverseScale := getBinaryExponent(inner, s)
 	denominator := s.esFactor
@@ -321,34 +569,84 @@ func getBinaryExponent(absValue float64, s *Type) (uint16, float64) {
 	// is filtered in the beginning of method "encode".
 	// If those checks fail to filter out values that are out of range,
 	// it will lead to an integer overflow.
-	for (biasedExponent > 0) && (xb*get(s.scale, biasedExponent-1) > absValue) {
+	for (biasedExponent > 0) && (xb*get(s.data.scale, biasedExponent-1) > absValue) {
 		biasedExponent--
 	}
 
 	// This is an exponential part of encoded number: b^x.
-	scale := get(s.scale, biasedExponent)
+	scale := get(s.data.scale, biasedExponent)
 	// By some reason, multiplying by a non-constant inverse number
 	// is faster, than division on my computer. So I return the inverse scale.
 	return biasedExponent << s.mSize, 1.0 / scale
 }
 
 func encodeDelta(last, x uint16, s *Type) int {
-	a := int(s.ToComparable(last))
-	b := int(s.ToComparable(x))
+	if !s.plain {
+		return encodeOptionsDelta(last, x, s)
+	}
+	return int(plainToComparable(x, s)) - int(plainToComparable(last, s))
+}
+
+// encodeOptionsDelta is encodeDelta for types made with options.
+func encodeOptionsDelta(last, x uint16, s *Type) int {
+	if (nil != s.data) && (nil != s.data.reserved) {
+		return encodeReservedDelta(last, x, s)
+	}
+
+	a := int(codeToComparable(last, s))
+	b := int(codeToComparable(x, s))
 	return b - a
 }
 
 func decodeDelta(last uint16, delta int, s *Type) uint16 {
-	lastComparable := int(s.ToComparable(last))
+	if !s.plain {
+		return decodeOptionsDelta(last, delta, s)
+	}
+
+	lastComparable := int(plainToComparable(last, s))
+
+	r := uint16(0)
+	if delta > int(s.bitmask)-lastComparable {
+		r = s.bitmask
+	} else if delta >= -lastComparable {
+		r = uint16(lastComparable + delta)
+	}
+
+	// It is fromComparable with KeepNegativeZero.
+	if s.minus != r&s.minus {
+		return ^r
+	}
+	return (^s.minus) & r
+}
+
+// decodeOptionsDelta is decodeDelta for types made with options.
+func decodeOptionsDelta(last uint16, delta int, s *Type) uint16 {
+	if (nil != s.data) && (nil != s.data.reserved) {
+		return applyTagPolicy(decodeReservedDelta(last, delta, s), last, s)
+	}
+
+	lastComparable := int(codeToComparable(last, s))
 
 	r := uint16(0)
 	if delta > int(s.bitmask)-lastComparable {
 		r = s.bitmask
 	} else if delta >= -lastComparable {
 		r = uint16(lastComparable + delta)
+	} else if NegativeZeroMarker == s.zeroMode {
+		// The lowest number, not the marker.
+		r = 1
 	}
 
-	return s.FromComparable(r)
+	return applyTagPolicy(comparableToCode(r, s), last, s)
+}
+
+// plainToComparable is toComparable with KeepNegativeZero.
+func plainToComparable(tf uint16, s *Type) uint16 {
+	r := ^tf
+	if 0 == tf&s.minus {
+		r = s.minus | tf
+	}
+	return r & s.bitmask
 }
 
 func isNegative(tf, minus uint16) bool {
@@ -367,10 +665,8 @@ func get(s []float64, i uint16) float64 {
 	// tests will never cover them.
 	maxIndex := len(s) - 1
 	if maxIndex < 0 {
-		println("unexpectedly empty slice")
 		return 0.0
 	} else if int(i) > maxIndex {
-		println("index out of bound")
 		return s[maxIndex]
 	}
 	return s[i]
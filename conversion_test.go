@@ -120,8 +120,8 @@ func makeTypeX3(length int, signed bool, t *testing.T) Type {
 	return tfType
 }
 
-func makeTypeX4(length int, signed bool, t *testing.T) Type {
-	tfType, err := NewTypeX4(length, signed)
+func makeTypeX4(length int, signed bool, t *testing.T, options ...Option) Type {
+	tfType, err := NewTypeX4(length, signed, options...)
 	if err != nil {
 		t.Fatal(err)
 	}
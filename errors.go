@@ -0,0 +1,134 @@
+package toyfloat
+
+import (
+	"errors"
+	"fmt"
+)
+
+// These errors describe invalid type parameters.
+// Constructors return them wrapped in a *ParamError,
+// so use errors.Is to check them.
+var (
+	ErrBaseOutOfRange = errors.New("only bases from 2 to 10 are supported")
+
+	ErrMinExponentNotNegative = errors.New("minX must be negative," +
+		" so that c=1/(1-xBase^minX) makes sense")
+
+	ErrMinExponentTooSmall = errors.New("xBase^-minX" +
+		" exceeds the range of float64")
+
+	ErrLengthTooLarge = errors.New("maximum length is 16 bits")
+
+	ErrNoMantissa = errors.New("mantissa must be at least 1 bit wide")
+
+	ErrRangeExceedsFloat64 = errors.New("maximum value" +
+		" xBase^(minX+2^xSize) exceeds the range of float64")
+
+	ErrTooFewCodes = errors.New("too few codes are left for finite values")
+
+	ErrInvalidReservedCode = errors.New("reserved code is out of range" +
+		" or is already used for NaN or infinity")
+
+	ErrInvalidNegativeZero = errors.New("unknown negative zero mode," +
+		" or unsigned type with a mode other than keep")
+
+	ErrTwosComplementUnsigned = errors.New("two's complement" +
+		" is only for signed types")
+
+	ErrInvalidLayout = errors.New("unknown field order," +
+		" or two's complement with the sign field not first")
+
+	ErrInvalidTagPolicy = errors.New("unknown tag policy," +
+		" or tags kept in the sign extension of two's complement")
+
+	ErrInvalidProtection = errors.New("unknown protection," +
+		" too few extra bits for it, or a tag policy with it")
+)
+
+// ParamError is returned by type constructors.
+// It tells which constraint failed and for which parameters.
+type ParamError struct {
+	Params Params
+	Err    error
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("%s (%s)", e.Err, e.Params)
+}
+
+// Unwrap returns one of the Err... values.
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// ErrNameRegistered is returned by Register for a name, which is already
+// given to other parameters. It is wrapped with the name.
+var ErrNameRegistered = errors.New("name is already registered")
+
+// These errors are returned by Widen and Narrow.
+var (
+	ErrIncompatibleTypes = errors.New("types are not compatible")
+
+	ErrMantissaWidth = errors.New("target mantissa is narrower than for Widen," +
+		" or wider than for Narrow")
+)
+
+// These errors describe invalid fields of a Packer.
+var (
+	ErrInvalidWidth = errors.New("packed word must be 16, 32 or 64 bits wide")
+
+	ErrFieldOutOfRange = errors.New("field does not fit into the word")
+
+	ErrFieldsOverlap = errors.New("fields overlap")
+)
+
+// These errors describe structs that Marshal and Unmarshal cannot handle,
+// and records that do not match them. They are wrapped
+// with the name of the field, so use errors.Is to check them.
+var (
+	ErrUnsupportedField = errors.New("field type is not supported")
+
+	ErrInvalidTag = errors.New("invalid toyfloat tag")
+
+	ErrValueOutOfRange = errors.New("integer does not fit into its bits")
+
+	ErrRecordLength = errors.New("record length does not match the struct")
+)
+
+// These errors are returned by ExportVectors and VerifyVectors.
+// VerifyVectors wraps them with the details, so use errors.Is to check them.
+var (
+	ErrVectorFormat = errors.New("unknown vector format")
+
+	ErrMalformedVectors = errors.New("vector file is malformed")
+
+	ErrVectorMismatch = errors.New("vector does not match the implementation")
+)
+
+// These errors are returned by OpenFileReader and FileWriter.
+var (
+	ErrNotToyfloatFile = errors.New("not a toyfloat container file")
+
+	ErrFileVersion = errors.New("unsupported container file version")
+
+	ErrFileChecksum = errors.New("container file checksum does not match")
+
+	ErrMalformedFile = errors.New("container file is malformed")
+
+	ErrFileClosed = errors.New("file writer is closed")
+
+	ErrFileCount = errors.New("number of codes does not match the count of the file")
+)
+
+// ErrInvalidCHeader is returned by WriteC, if the prefix is not
+// a C identifier, or the fixed-point numbers cannot hold the type.
+var ErrInvalidCHeader = errors.New("C prefix is not an identifier," +
+	" or fixed-point numbers do not fit the type")
+
+// ErrCorruptedCode is returned by Verify, if the check bits
+// do not match the code, and the error cannot be corrected.
+var ErrCorruptedCode = errors.New("code is corrupted")
+
+// ErrInvalidType is returned by the Try... methods of the zero Type.
+var ErrInvalidType = errors.New("type is not initialized," +
+	" use one of the constructors")
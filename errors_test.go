@@ -0,0 +1,61 @@
+package toyfloat
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParamErrors(t *testing.T) {
+	params := func(length, xBase, xSize uint8, minX int, signed bool) Params {
+		return Params{Length: length, XBase: xBase, XSize: xSize,
+			MinX: minX, Signed: signed}
+	}
+
+	tests := []struct {
+		params Params
+		err    error
+	}{
+		{params(12, 1, 4, -8, true), ErrBaseOutOfRange},
+		{params(12, 11, 4, -8, true), ErrBaseOutOfRange},
+		{params(12, 2, 4, 0, true), ErrMinExponentNotNegative},
+		{params(12, 2, 4, -1024, true), ErrMinExponentTooSmall},
+		{params(12, 10, 4, -309, true), ErrMinExponentTooSmall},
+		{params(12, 2, 4, -int(^uint(0)>>1)-1, true), ErrMinExponentTooSmall},
+		{params(17, 2, 4, -8, true), ErrLengthTooLarge},
+		{params(5, 2, 4, -8, true), ErrNoMantissa},
+		{params(4, 2, 4, -8, false), ErrNoMantissa},
+		{params(16, 3, 10, -1, true), ErrRangeExceedsFloat64},
+		{params(16, 10, 9, -203, true), ErrRangeExceedsFloat64},
+	}
+
+	for _, tt := range tests {
+		_, err := NewTypeFromParams(tt.params)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%+v: %v is not %v", tt.params, err, tt.err)
+		}
+
+		var paramError *ParamError
+		if !errors.As(err, &paramError) {
+			t.Fatalf("%+v: %v is not a *ParamError", tt.params, err)
+		}
+
+		if paramError.Params.String() != tt.params.String() {
+			t.Fatalf("%+v != %+v", paramError.Params, tt.params)
+		}
+
+		if strings.ContainsAny(err.Error(), "\n") {
+			t.Fatalf("%q has a newline", err.Error())
+		}
+	}
+
+	_, err := NewTypeX4(4, false)
+	if !errors.Is(err, ErrNoMantissa) {
+		t.Fatalf("%v is not ErrNoMantissa", err)
+	}
+
+	_, err = ParseType("s12x4b11m-8")
+	if !errors.Is(err, ErrBaseOutOfRange) {
+		t.Fatalf("%v is not ErrBaseOutOfRange", err)
+	}
+}
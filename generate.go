@@ -0,0 +1,290 @@
+package toyfloat
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteGo writes Go source of two functions,
+// func encode(float64) uint16 and func decode(uint16) float64,
+// which return exactly what the Encode and Decode methods of the type do.
+// The constants of the type are inlined, and the exponent
+// is found by an unrolled binary search.
+//
+// Only declarations are written. They need the package "math" imported,
+// and they declare helpers named after the functions,
+// such as encodeMagnitude and decodeScale.
+func WriteGo(w io.Writer, t Type, encode, decode string) error {
+	if !t.IsValid() {
+		return ErrInvalidType
+	}
+
+	var b bytes.Buffer
+	writeGoEncoder(&b, &t, encode)
+	writeGoDecoder(&b, &t, decode)
+
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(source)
+	return err
+}
+
+// ----------------
+
+// goFloat prints a float64 literal that converts back exactly.
+func goFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		return s + ".0"
+	}
+	return s
+}
+
+// goHelper names an unexported helper of the function.
+func goHelper(function, suffix string) string {
+	return strings.ToLower(function[:1]) + function[1:] + suffix
+}
+
+func writeGoEncoder(b *bytes.Buffer, t *Type, name string) {
+	a := t.data.scale[0]
+
+	fmt.Fprintf(b, "// %s is Encode of the type %s.\n", name, t.Spec())
+	fmt.Fprintf(b, "func %s(v float64) uint16 {\n", name)
+	fmt.Fprintf(b, "var code uint16\n")
+	fmt.Fprintf(b, "switch {\n")
+	fmt.Fprintf(b, "case math.IsNaN(v):\n code = 0x%X\n", t.nanCode)
+
+	fmt.Fprintf(b, "case v > %s:\n code = 0x%X\n", goFloat(t.maxValue), t.maxMagnitude)
+	if t.nonFinite {
+		fmt.Fprintf(b, "if math.IsInf(v, +1) {\n code = 0x%X\n}\n", t.infCode)
+	}
+
+	if 0 == t.minus {
+		fmt.Fprintf(b, "case v < 0:\n code = 0x0\n")
+	} else {
+		fmt.Fprintf(b, "case v < %s:\n code = 0x%X\n",
+			goFloat(t.minValue), t.minus|t.maxMagnitude)
+		if t.nonFinite {
+			fmt.Fprintf(b, "if math.IsInf(v, -1) {\n code = 0x%X\n}\n", t.minus|t.infCode)
+		}
+
+		fmt.Fprintf(b, "case v < 0:\n code = 0x%X | %s(%s - v*%s)\n",
+			t.minus, goHelper(name, "Magnitude"), goFloat(a), goFloat(1.0-a))
+	}
+
+	fmt.Fprintf(b, "default:\n code = %s(%s + v*%s)\n",
+		goHelper(name, "Magnitude"), goFloat(a), goFloat(1.0-a))
+	fmt.Fprintf(b, "}\n\n")
+
+	if (KeepNegativeZero != t.zeroMode) && (0 != t.minus) {
+		fmt.Fprintf(b, "if code == 0x%X {\n code = 0x0\n}\n\n", t.minus)
+	}
+
+	if nil != t.data.reserved {
+		writeGoAvoidReserved(b, t)
+	}
+
+	if t.twos {
+		fmt.Fprintf(b, "// Two's complement.\n")
+		fmt.Fprintf(b, "if 0 == code&0x%X {\n code |= 0x%X\n} else {\n", t.minus, t.minus)
+		switch t.zeroMode {
+		case KeepNegativeZero:
+			fmt.Fprintf(b, "code = ^code\n")
+		case CanonicalZero:
+			fmt.Fprintf(b, "code = ^code + 1\n")
+		case NegativeZeroMarker:
+			fmt.Fprintf(b, "if code == 0x%X {\n code = 0x0\n} else {\n code = ^code + 1\n}\n", t.minus)
+		}
+		fmt.Fprintf(b, "}\n")
+		fmt.Fprintf(b, "code = (code & 0x%X) ^ 0x%X\n", t.bitmask, t.minus)
+		fmt.Fprintf(b, "if 0 != code&0x%X {\n code |= 0x%X\n}\n\n", t.minus, ^t.bitmask)
+	}
+
+	if t.rearranged {
+		fmt.Fprintf(b, "// The layout %s.\n", t.layout)
+		fmt.Fprintf(b, "x := code & 0x%X\n", t.bitmask)
+		fmt.Fprintf(b, "code = (x>>%d&0x%X)<<%d | (x&0x%X)<<%d\n",
+			t.mSize, t.xMask, t.xShift, t.mMask, t.mShift)
+		if 0 != t.minus {
+			fmt.Fprintf(b, "if 0 != x&0x%X {\n code |= 0x%X\n}\n", t.minus, uint16(1)<<t.sShift)
+		}
+		if 0 != t.align {
+			fmt.Fprintf(b, "code <<= %d\n", t.align)
+		}
+		fmt.Fprintf(b, "\n")
+	}
+
+	if NoProtection != t.protection {
+		fmt.Fprintf(b, "// The check bits of %s.\n", t.protection)
+		fmt.Fprintf(b, "value := (code >> %d) & 0x%X\n", t.align, t.bitmask)
+		fmt.Fprintf(b, "check := uint16(0)\n")
+		fmt.Fprintf(b, "for i, column := range %s {\n", goHelper(name, "Check"))
+		fmt.Fprintf(b, "if 0 != (value>>uint(i))&1 {\n check ^= column\n}\n}\n")
+		fmt.Fprintf(b, "return code&0x%X | (check<<%d)&0x%X\n", t.bitmask<<t.align,
+			t.tagShift(), t.spareMask())
+	} else {
+		fmt.Fprintf(b, "return code\n")
+	}
+	fmt.Fprintf(b, "}\n\n")
+
+	writeGoMagnitude(b, t, goHelper(name, "Magnitude"))
+
+	if NoProtection != t.protection {
+		// Check bits are linear: they are the XOR
+		// of the check bits of the single bits.
+		fmt.Fprintf(b, "var %s = [%d]uint16{", goHelper(name, "Check"), t.length)
+		for i := uint8(0); i < t.length; i++ {
+			fmt.Fprintf(b, "0x%X, ", checkBits(uint16(1)<<i, t))
+		}
+		fmt.Fprintf(b, "}\n\n")
+	}
+}
+
+// writeGoAvoidReserved replaces reserved codes with the nearest free ones,
+// the way avoidReserved does.
+func writeGoAvoidReserved(b *bytes.Buffer, t *Type) {
+	r := t.data.reserved
+
+	fmt.Fprintf(b, "// Reserved codes.\n")
+	fmt.Fprintf(b, "switch code {\n")
+	for code, reserved := range r.is {
+		if !reserved {
+			continue
+		}
+
+		rank := int(r.rank[toComparable(uint16(code), t)])
+		fmt.Fprintf(b, "case 0x%X:\n", code)
+
+		if rank == 0 {
+			fmt.Fprintf(b, "code = 0x%X\n", fromComparable(r.free[0], t)&t.bitmask)
+		} else if rank == len(r.free) {
+			fmt.Fprintf(b, "code = 0x%X\n", fromComparable(r.free[rank-1], t)&t.bitmask)
+		} else {
+			below := fromComparable(r.free[rank-1], t) & t.bitmask
+			above := fromComparable(r.free[rank], t) & t.bitmask
+			fmt.Fprintf(b, "if math.Abs(%s-v) < math.Abs(%s-v) {\n code = 0x%X\n} else {\n code = 0x%X\n}\n",
+				goFloat(decodeNumber(below, t)), goFloat(decodeNumber(above, t)), below, above)
+		}
+	}
+	fmt.Fprintf(b, "}\n\n")
+}
+
+// writeGoMagnitude writes encodeInnerValue with getBinaryExponent unrolled.
+func writeGoMagnitude(b *bytes.Buffer, t *Type, name string) {
+	fmt.Fprintf(b, "func %s(inner float64) uint16 {\n", name)
+	writeGoExponentSearch(b, t, 0, t.xMask)
+	fmt.Fprintf(b, "}\n\n")
+}
+
+// writeGoExponentSearch finds the maximum exponent from lo to hi,
+// which is lo or has its boundary not above the value.
+// The boundaries grow with the exponent, so the search is binary.
+func writeGoExponentSearch(b *bytes.Buffer, t *Type, lo, hi uint16) {
+	if lo == hi {
+		denominator := goFloat(t.esFactor)
+		inverseScale := 1.0 / get(t.data.scale, lo)
+		fmt.Fprintf(b, "return uint16(%s*inner*%s - %s + 0.499999999999) | 0x%X\n",
+			denominator, goFloat(inverseScale), denominator, lo<<t.mSize)
+		return
+	}
+
+	middle := lo + (hi-lo+1)/2
+	boundary := t.xBoundary * get(t.data.scale, middle-1)
+
+	fmt.Fprintf(b, "if inner >= %s {\n", goFloat(boundary))
+	writeGoExponentSearch(b, t, middle, hi)
+	fmt.Fprintf(b, "}\n")
+	writeGoExponentSearch(b, t, lo, middle-1)
+}
+
+func writeGoDecoder(b *bytes.Buffer, t *Type, name string) {
+	a := t.data.scale[0]
+	c := 1.0 / (1.0 - a)
+
+	fmt.Fprintf(b, "// %s is Decode of the type %s.\n", name, t.Spec())
+	fmt.Fprintf(b, "func %s(code uint16) float64 {\n", name)
+
+	if t.rearranged {
+		fmt.Fprintf(b, "// The layout %s.\n", t.layout)
+		fmt.Fprintf(b, "p := (code >> %d) & 0x%X\n", t.align, t.bitmask)
+		fmt.Fprintf(b, "x := (p>>%d&0x%X)<<%d | p>>%d&0x%X",
+			t.xShift, t.xMask, t.mSize, t.mShift, t.mMask)
+		if 0 != t.minus {
+			fmt.Fprintf(b, " | (p>>%d&1)<<%d", t.sShift, t.length-1)
+		}
+		fmt.Fprintf(b, "\n\n")
+	} else {
+		fmt.Fprintf(b, "x := code & 0x%X\n\n", t.bitmask)
+	}
+
+	if t.twos {
+		fmt.Fprintf(b, "// Two's complement.\n")
+		fmt.Fprintf(b, "c := (x ^ 0x%X) & 0x%X\n", t.minus, t.bitmask)
+		fmt.Fprintf(b, "if 0 == c&0x%X {\n", t.minus)
+		switch t.zeroMode {
+		case KeepNegativeZero:
+			fmt.Fprintf(b, "x = ^c\n")
+		case CanonicalZero:
+			fmt.Fprintf(b, "if 0 == c {\n c = 1\n}\n x = ^(c - 1)\n")
+		case NegativeZeroMarker:
+			fmt.Fprintf(b, "if 0 == c {\n x = 0x%X\n} else {\n x = ^(c - 1)\n}\n", t.minus)
+		}
+		fmt.Fprintf(b, "} else {\n x = c &^ 0x%X\n}\n", t.minus)
+		fmt.Fprintf(b, "x &= 0x%X\n\n", t.bitmask)
+	}
+
+	if nil != t.data.reserved {
+		fmt.Fprintf(b, "switch x {\n case ")
+		first := true
+		for code, reserved := range t.data.reserved.is {
+			if reserved {
+				if !first {
+					fmt.Fprintf(b, ", ")
+				}
+				fmt.Fprintf(b, "0x%X", code)
+				first = false
+			}
+		}
+		fmt.Fprintf(b, ":\n return math.NaN()\n}\n\n")
+	}
+
+	if (KeepNegativeZero != t.zeroMode) && (0 != t.minus) {
+		result := "0.0"
+		if NegativeZeroMarker == t.zeroMode {
+			result = "math.NaN()"
+		}
+		fmt.Fprintf(b, "if x == 0x%X {\n return %s\n}\n\n", t.minus, result)
+	}
+
+	if t.nonFinite {
+		fmt.Fprintf(b, "switch x & 0x%X {\n", t.infCode)
+		fmt.Fprintf(b, "case 0x%X:\n", t.infCode)
+		if 0 != t.minus {
+			fmt.Fprintf(b, "if 0 != x&0x%X {\n return math.Inf(-1)\n}\n", t.minus)
+		}
+		fmt.Fprintf(b, "return math.Inf(+1)\n")
+		fmt.Fprintf(b, "case 0x%X:\n return math.NaN()\n}\n\n", t.nanCode)
+	}
+
+	fmt.Fprintf(b, "v := ((1.0+float64(x&0x%X)*%s)*%s[(x>>%d)&0x%X] - %s) * %s\n",
+		t.mMask, goFloat(t.dsFactor), goHelper(name, "Scale"), t.mSize, t.xMask, goFloat(a), goFloat(c))
+	fmt.Fprintf(b, "if ((1.0 - 1e-14) < v) && (v < (1.0 + 1e-14)) {\n v = 1.0\n}\n")
+
+	if 0 != t.minus {
+		fmt.Fprintf(b, "if 0 != x&0x%X {\n return -v\n}\n", t.minus)
+	}
+	fmt.Fprintf(b, "return v\n}\n\n")
+
+	fmt.Fprintf(b, "var %s = [%d]float64{\n", goHelper(name, "Scale"), len(t.data.scale))
+	for _, scale := range t.data.scale {
+		fmt.Fprintf(b, "%s,\n", goFloat(scale))
+	}
+	fmt.Fprintf(b, "}\n")
+}
@@ -0,0 +1,48 @@
+package toyfloat
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestWriteGo(t *testing.T) {
+	specs := []string{
+		"s12x4b2m-8",
+		"u5x3b2m-6",
+		"s16x2b3m-3+nonfinite+zero=canonical",
+		"s10x3b2m-6+nonfinite+zero=marker+twos+layout=sxm.msb+protect=crc4",
+		"s9x2b3m-3+reserved=1.2+layout=xms",
+	}
+
+	for _, spec := range specs {
+		tf, err := ParseType(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b bytes.Buffer
+		if err := WriteGo(&b, tf, "EncodeX", "decodeX"); err != nil {
+			t.Fatal(err)
+		}
+
+		source := "package x\n\nimport \"math\"\n\n" + b.String()
+		if _, err := parser.ParseFile(token.NewFileSet(), "x.go", source, 0); err != nil {
+			t.Fatalf("%s: %v", spec, err)
+		}
+
+		for _, name := range []string{"func EncodeX(", "func encodeXMagnitude(",
+			"func decodeX(", "decodeXScale = [", tf.Spec()} {
+
+			if !strings.Contains(source, name) {
+				t.Fatalf("%s: no %s", spec, name)
+			}
+		}
+	}
+
+	if err := WriteGo(&bytes.Buffer{}, Type{}, "e", "d"); err != ErrInvalidType {
+		t.Fatalf("ErrInvalidType expected, got %v", err)
+	}
+}
@@ -0,0 +1,315 @@
+package toyfloat
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// VectorFormat is the file format of ExportVectors.
+type VectorFormat uint8
+
+const (
+	// JSONVectors is an object with the spec of the type in "type",
+	// and the rows in "codes". All numbers but the codes are strings,
+	// since JSON has no NaN and infinities.
+	JSONVectors VectorFormat = iota
+
+	// CSVVectors has a header and a row per code.
+	// The first column is the spec of the type.
+	CSVVectors
+)
+
+// String returns the name of the format.
+func (f VectorFormat) String() string {
+	switch f {
+	case JSONVectors:
+		return "json"
+	case CSVVectors:
+		return "csv"
+	}
+	return "invalid"
+}
+
+// ExportVectors writes golden test vectors of the type,
+// which ports to other languages can be checked against.
+//
+// There is a row per code, in the order of the codes without extra bits.
+// The codes are the ones Encode returns, with the sign extension
+// of WithTwosComplement and the check bits of WithProtection.
+// A row has:
+//
+//	code   the code as a decimal number;
+//	exact  its exact rational value, such as "-3/256", or "" for NaN and infinities;
+//	value  what Decode returns, "NaN", "+Inf", "-Inf", "-0", "0.5", etc.;
+//	low    the lowest float64, that Encode turns into the code;
+//	high   the highest one, or both are "", if Encode never returns the code.
+//
+// Floats are printed with strconv.FormatFloat(v, 'g', -1, 64),
+// so they are read back exactly. Both zeros encode the same way,
+// so the bounds are never "-0".
+func ExportVectors(t Type, w io.Writer, format VectorFormat) error {
+	if !t.IsValid() {
+		return ErrInvalidType
+	} else if format > CSVVectors {
+		return ErrVectorFormat
+	}
+
+	rows := makeGoldenRows(&t)
+	bw := bufio.NewWriter(w)
+
+	if JSONVectors == format {
+		file := goldenFile{Type: t.Spec(), Codes: rows}
+		e := json.NewEncoder(bw)
+		e.SetIndent("", " ")
+		if err := e.Encode(&file); err != nil {
+			return err
+		}
+		return bw.Flush()
+	}
+
+	cw := csv.NewWriter(bw)
+	if err := cw.Write(goldenHeader); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := cw.Write(row.record(t.Spec())); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// VerifyVectors reads a file written by ExportVectors in either format,
+// and checks it against this version of the package.
+// It returns ErrMalformedVectors, if the file cannot be read,
+// and ErrVectorMismatch, if a row differs.
+// Both are wrapped with the details, so use errors.Is to check them.
+func VerifyVectors(r io.Reader) error {
+	br := bufio.NewReader(r)
+
+	var file goldenFile
+	if first, err := firstNonSpace(br); err != nil {
+		return fmt.Errorf("%v: %w", err, ErrMalformedVectors)
+	} else if '{' == first {
+		if err := json.NewDecoder(br).Decode(&file); err != nil {
+			return fmt.Errorf("%v: %w", err, ErrMalformedVectors)
+		}
+	} else if err := readGoldenCSV(br, &file); err != nil {
+		return err
+	}
+
+	t, err := ParseType(file.Type)
+	if err != nil {
+		return fmt.Errorf("type %q: %v: %w", file.Type, err, ErrMalformedVectors)
+	}
+
+	expected := makeGoldenRows(&t)
+	if len(file.Codes) != len(expected) {
+		return fmt.Errorf("%d rows instead of %d: %w",
+			len(file.Codes), len(expected), ErrVectorMismatch)
+	}
+
+	for i, row := range file.Codes {
+		if row != expected[i] {
+			return fmt.Errorf("%s: %+v, expected %+v: %w",
+				file.Type, row, expected[i], ErrVectorMismatch)
+		}
+	}
+	return nil
+}
+
+// ----------------
+
+type goldenFile struct {
+	Type  string      `json:"type"`
+	Codes []goldenRow `json:"codes"`
+}
+
+type goldenRow struct {
+	Code  uint16 `json:"code"`
+	Exact string `json:"exact"`
+	Value string `json:"value"`
+	Low   string `json:"low"`
+	High  string `json:"high"`
+}
+
+var goldenHeader = []string{"type", "code", "exact", "value", "low", "high"}
+
+func (row *goldenRow) record(spec string) []string {
+	return []string{spec, strconv.Itoa(int(row.Code)), row.Exact, row.Value, row.Low, row.High}
+}
+
+func firstNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		} else if (' ' != c) && ('\t' != c) && ('\r' != c) && ('\n' != c) {
+			return c, r.UnreadByte()
+		}
+	}
+}
+
+func readGoldenCSV(r io.Reader, file *goldenFile) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(goldenHeader)
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return fmt.Errorf("%v: %w", err, ErrMalformedVectors)
+	} else if 0 == len(records) {
+		return fmt.Errorf("no header: %w", ErrMalformedVectors)
+	}
+
+	for i, name := range goldenHeader {
+		if records[0][i] != name {
+			return fmt.Errorf("column %q instead of %q: %w",
+				records[0][i], name, ErrMalformedVectors)
+		}
+	}
+
+	for _, record := range records[1:] {
+		if "" == file.Type {
+			file.Type = record[0]
+		} else if record[0] != file.Type {
+			return fmt.Errorf("types %q and %q: %w", file.Type, record[0], ErrMalformedVectors)
+		}
+
+		code, err := strconv.ParseUint(record[1], 10, 16)
+		if err != nil {
+			return fmt.Errorf("code %q: %w", record[1], ErrMalformedVectors)
+		}
+
+		file.Codes = append(file.Codes, goldenRow{
+			Code: uint16(code), Exact: record[2], Value: record[3],
+			Low: record[4], High: record[5]})
+	}
+	return nil
+}
+
+func makeGoldenRows(t *Type) []goldenRow {
+	size := int(t.bitmask) + 1
+
+	// first[r] is the lowest key, that encodes to the comparable r or above.
+	// Encode is monotone, so the bin of r is from first[r] to first[r+1]-1.
+	first := make([]int64, size+1)
+	for r := range first {
+		first[r] = searchFloatKey(t, r)
+	}
+
+	rows := make([]goldenRow, 0, size)
+	for i := 0; i < size; i++ {
+		x := fromLayout(uint16(i)<<t.align, t) & t.bitmask
+		code := toLayout(x, t)
+		if NoProtection != t.protection {
+			code = t.Protect(code)
+		}
+
+		row := goldenRow{Code: code, Value: formatGolden(t.Decode(code))}
+		if exact := exactValue(x, t); nil != exact {
+			row.Exact = exact.RatString()
+		}
+
+		// Codes such as -0 of CanonicalZero types share the comparable form.
+		r := int(codeToComparable(code, t))
+		low, high := first[r], first[r+1]-1
+		if (low <= high) && (encode(floatOfKey(low), t) == code) {
+			row.Low = formatGolden(floatOfKey(low))
+			row.High = formatGolden(floatOfKey(high))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func formatGolden(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Keys order float64 numbers as integers, and both zeros are 0.
+func keyOfFloat(v float64) int64 {
+	if 0 == v {
+		return 0
+	}
+	k := int64(math.Float64bits(math.Abs(v)))
+	if v < 0 {
+		return -k
+	}
+	return k
+}
+
+func floatOfKey(k int64) float64 {
+	if k < 0 {
+		return -math.Float64frombits(uint64(-k))
+	}
+	return math.Float64frombits(uint64(k))
+}
+
+// searchFloatKey returns the lowest key of a float64 from -Inf to +Inf,
+// which encodes to the comparable r or above, or the key after +Inf.
+func searchFloatKey(t *Type, r int) int64 {
+	low, high := keyOfFloat(math.Inf(-1)), keyOfFloat(math.Inf(+1))+1
+	for low < high {
+		// The difference does not fit into int64.
+		middle := low + int64((uint64(high)-uint64(low))/2)
+		if int(codeToComparable(encode(floatOfKey(middle), t), t)) >= r {
+			high = middle
+		} else {
+			low = middle + 1
+		}
+	}
+	return low
+}
+
+// exactValue returns the value of a sign–magnitude code without extra bits,
+// as decodeNumber computes it, or nil for NaN and infinities.
+func exactValue(x uint16, t *Type) *big.Rat {
+	if (nil != t.data.reserved) && t.data.reserved.is[x] {
+		return nil
+	} else if (KeepNegativeZero != t.zeroMode) && (x == t.minus) {
+		if NegativeZeroMarker == t.zeroMode {
+			return nil
+		}
+		return new(big.Rat)
+	} else if t.nonFinite && ((x&t.infCode == t.infCode) || (x&t.infCode == t.nanCode)) {
+		return nil
+	}
+
+	a := exactPower(t, 0)
+	scale := exactPower(t, int((x>>t.mSize)&t.xMask))
+
+	// ((2^M + (b-1)m) / 2^M * scale - a) / (1 - a)
+	v := new(big.Rat).SetInt64(int64(t.xBase-1) * int64(x&t.mMask))
+	v.Add(v, new(big.Rat).SetInt64(int64(1)<<t.mSize))
+	v.Quo(v, new(big.Rat).SetInt64(int64(1)<<t.mSize))
+	v.Mul(v, scale)
+	v.Sub(v, a)
+	v.Quo(v, new(big.Rat).Sub(big.NewRat(1, 1), a))
+
+	if isNegative(x, t.minus) {
+		v.Neg(v)
+	}
+	return v
+}
+
+// exactPower returns b^(minX+x).
+func exactPower(t *Type, x int) *big.Rat {
+	power := t.minX + x
+	b := big.NewInt(int64(t.xBase))
+
+	if power < 0 {
+		d := new(big.Int).Exp(b, big.NewInt(int64(-power)), nil)
+		return new(big.Rat).SetFrac(big.NewInt(1), d)
+	}
+	return new(big.Rat).SetInt(new(big.Int).Exp(b, big.NewInt(int64(power)), nil))
+}
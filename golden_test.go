@@ -0,0 +1,148 @@
+package toyfloat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestExportVectors(t *testing.T) {
+	specs := []string{
+		"s12x4b2m-8",
+		"u5x3b2m-6",
+		"s8x2b3m-3+nonfinite+zero=canonical",
+		"s10x3b2m-6+nonfinite+zero=marker+twos+layout=sxm.msb+protect=crc4",
+		"s9x2b3m-3+reserved=1.2+layout=xms+protect=hamming",
+	}
+
+	for _, spec := range specs {
+		tf, err := ParseType(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, format := range []VectorFormat{JSONVectors, CSVVectors} {
+			var b bytes.Buffer
+			if err := ExportVectors(tf, &b, format); err != nil {
+				t.Fatalf("%s, %s: %v", spec, format, err)
+			} else if err := VerifyVectors(bytes.NewReader(b.Bytes())); err != nil {
+				t.Fatalf("%s, %s: %v", spec, format, err)
+			}
+		}
+
+		var b bytes.Buffer
+		if err := ExportVectors(tf, &b, JSONVectors); err != nil {
+			t.Fatal(err)
+		}
+
+		var file goldenFile
+		if err := json.Unmarshal(b.Bytes(), &file); err != nil {
+			t.Fatal(err)
+		} else if file.Type != tf.Spec() || len(file.Codes) != 1<<tf.length {
+			t.Fatalf("%s: %s, %d rows", spec, file.Type, len(file.Codes))
+		}
+
+		for _, row := range file.Codes {
+			checkGoldenRow(&tf, row, t)
+		}
+	}
+}
+
+func checkGoldenRow(tf *Type, row goldenRow, t *testing.T) {
+	value := tf.Decode(row.Code)
+	if formatGolden(value) != row.Value {
+		t.Fatalf("%+v: value %v", row, value)
+	}
+
+	if "" != row.Exact {
+		exact, ok := new(big.Rat).SetString(row.Exact)
+		if !ok {
+			t.Fatalf("%+v: exact", row)
+		} else if f, _ := exact.Float64(); math.Abs(f-value) > 1e-14*math.Abs(f) {
+			t.Fatalf("%+v: %v != %v", row, f, value)
+		}
+	} else if !math.IsNaN(value) && !math.IsInf(value, 0) {
+		t.Fatalf("%+v: no exact value", row)
+	}
+
+	if "" == row.Low {
+		return
+	}
+
+	low, err := strconv.ParseFloat(row.Low, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	high, err := strconv.ParseFloat(row.High, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tf.Encode(low) != row.Code || tf.Encode(high) != row.Code {
+		t.Fatalf("%+v: the bounds encode to 0x%X, 0x%X", row, tf.Encode(low), tf.Encode(high))
+	} else if (0 != value) && !((low <= value) && (value <= high)) {
+		// The bin of -0 is below zero.
+		t.Fatalf("%+v: out of the bin", row)
+	}
+
+	if below := math.Nextafter(low, math.Inf(-1)); (0 != low) && !math.IsInf(low, -1) &&
+		tf.Encode(below) == row.Code {
+
+		t.Fatalf("%+v: %v encodes to the code too", row, below)
+	}
+	if above := math.Nextafter(high, math.Inf(+1)); (0 != high) && !math.IsInf(high, +1) &&
+		tf.Encode(above) == row.Code {
+
+		t.Fatalf("%+v: %v encodes to the code too", row, above)
+	}
+}
+
+func TestVerifyVectorsErrors(t *testing.T) {
+	tf := makeTypeX4(12, true, t)
+
+	var b bytes.Buffer
+	if err := ExportVectors(tf, &b, CSVVectors); err != nil {
+		t.Fatal(err)
+	}
+	csv := b.String()
+
+	// Another rounding of one of the bins.
+	lines := strings.Split(csv, "\n")
+	fields := strings.Split(lines[100], ",")
+	fields[5] = "1"
+	lines[100] = strings.Join(fields, ",")
+
+	mismatches := []string{
+		strings.Join(lines, "\n"),
+		strings.Join(lines[:50], "\n"),
+	}
+	for _, s := range mismatches {
+		if err := VerifyVectors(strings.NewReader(s)); !errors.Is(err, ErrVectorMismatch) {
+			t.Fatalf("ErrVectorMismatch expected, got %v", err)
+		}
+	}
+
+	malformed := []string{
+		"",
+		"{\"type\": 5}",
+		"type,code\n",
+		strings.Replace(csv, "s12x4b2m-8", "s12x4", -1),
+		strings.Replace(csv, "s12x4b2m-8,10,", "s12x4b2m-8,x,", 1),
+	}
+	for _, s := range malformed {
+		if err := VerifyVectors(strings.NewReader(s)); !errors.Is(err, ErrMalformedVectors) {
+			t.Fatalf("%.40q: ErrMalformedVectors expected, got %v", s, err)
+		}
+	}
+
+	if err := ExportVectors(tf, &b, VectorFormat(2)); err != ErrVectorFormat {
+		t.Fatalf("ErrVectorFormat expected, got %v", err)
+	} else if err := ExportVectors(Type{}, &b, CSVVectors); err != ErrInvalidType {
+		t.Fatalf("ErrInvalidType expected, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package toyfloat
+
+import "sync"
+
+// typeData is the part of a type that does not fit
+// into a comparable struct. It is interned, so types made
+// with the same arguments share the same pointer and compare equal.
+type typeData struct {
+	scale    []float64
+	reserved *reservedTable
+	tables   lookupTables
+}
+
+// internBudget limits the memory of interned data in bytes,
+// including the lookup tables it may build later.
+// Types made beyond it are not interned: they work,
+// but compare equal only to their copies.
+// So specs of untrusted files cannot make the table grow forever.
+const internBudget = 32 << 20
+
+var interned = struct {
+	sync.Mutex
+	data map[paramsKey]*typeData
+	size int
+}{data: make(map[paramsKey]*typeData)}
+
+// internTypeData returns the data for the parameters, calling makeData
+// only if there is no such data yet.
+// It never forgets a key: a program uses few distinct types.
+func internTypeData(p *Params, makeData func() *typeData) *typeData {
+	key := p.key()
+
+	interned.Lock()
+	defer interned.Unlock()
+
+	data, ok := interned.data[key]
+	if !ok {
+		data = makeData()
+		if size := data.size(p.Length); interned.size+size <= internBudget {
+			interned.data[key] = data
+			interned.size += size
+		}
+	}
+	return data
+}
+
+// size returns the number of bytes the data takes,
+// with the lookup tables of a type of the length.
+func (d *typeData) size(length uint8) int {
+	n := 8 * len(d.scale)
+	if r := d.reserved; nil != r {
+		n += 2*len(r.codes) + len(r.is) + 2*len(r.rank) + 2*cap(r.free)
+	}
+
+	n += 8 << length
+	if length <= productTableMaxLength {
+		n += 8 << (2 * length)
+	}
+	return n
+}
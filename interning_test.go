@@ -0,0 +1,110 @@
+package toyfloat
+
+import "testing"
+
+func TestTypesAreComparable(t *testing.T) {
+	a := makeTypeX4(12, true, t)
+	b := makeTypeX4(12, true, t)
+
+	if a != b {
+		t.Fatalf("types made with the same arguments must be equal")
+	}
+
+	c, err := NewType(12, 2, 4, -8, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a != c {
+		t.Fatalf("NewTypeX4 must be equal to the same NewType")
+	}
+
+	different := []Type{
+		makeTypeX4(12, false, t),
+		makeTypeX4(13, true, t),
+		makeTypeX3(12, true, t),
+	}
+
+	for i, d := range different {
+		if a == d {
+			t.Fatalf("#%d must not be equal", i)
+		}
+	}
+
+	{
+		d, err := NewType(12, 3, 4, -8, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if a == d {
+			t.Fatalf("different bases must not be equal")
+		}
+	}
+
+	{
+		d, err := NewType(12, 2, 4, -7, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if a == d {
+			t.Fatalf("different minX must not be equal")
+		}
+	}
+}
+
+func TestTypeAsMapKey(t *testing.T) {
+	registry := map[Type]string{}
+	registry[makeTypeX4(12, true, t)] = "12"
+	registry[makeTypeX4(12, false, t)] = "12u"
+
+	if registry[makeTypeX4(12, true, t)] != "12" {
+		t.Fatalf("12 is not found")
+	}
+
+	if registry[makeTypeX4(12, false, t)] != "12u" {
+		t.Fatalf("12u is not found")
+	}
+
+	if len(registry) != 2 {
+		t.Fatalf("%d keys", len(registry))
+	}
+}
+
+func TestInternBudget(t *testing.T) {
+	interned.Lock()
+	size := interned.size
+	interned.size = internBudget
+	interned.Unlock()
+
+	defer func() {
+		interned.Lock()
+		interned.size = size
+		interned.Unlock()
+	}()
+
+	// A type no other test makes.
+	a, err := ParseType("s11x5b3m-17")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ParseType("s11x5b3m-17")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a == b {
+		t.Fatalf("types beyond the budget must not be interned")
+	} else if c := a; c != a {
+		t.Fatalf("copies must be equal")
+	} else if a.Decode(a.Encode(0.5)) != b.Decode(b.Encode(0.5)) {
+		t.Fatalf("types beyond the budget must work the same")
+	}
+
+	interned.Lock()
+	defer interned.Unlock()
+	if interned.size != internBudget {
+		t.Fatalf("%d bytes", interned.size)
+	}
+}
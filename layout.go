@@ -0,0 +1,171 @@
+package toyfloat
+
+// FieldOrder is the order of the sign, exponent and mantissa fields
+// in a code, from the most significant one.
+type FieldOrder uint8
+
+// Unsigned types have no sign field, so for them
+// OrderSXM, OrderXSM and OrderXMS are the same,
+// and so are OrderSMX, OrderMSX and OrderMXS.
+const (
+	// OrderSXM is the default, which is used since version 1.0.
+	OrderSXM FieldOrder = iota
+	OrderSMX
+	OrderXSM
+	OrderXMS
+	OrderMSX
+	OrderMXS
+)
+
+var fieldOrderNames = [...]string{"sxm", "smx", "xsm", "xms", "msx", "mxs"}
+
+// String returns the field names in order, such as "sxm".
+func (o FieldOrder) String() string {
+	if int(o) < len(fieldOrderNames) {
+		return fieldOrderNames[o]
+	}
+	return "invalid"
+}
+
+// Layout describes where the fields are in a code.
+// The zero Layout is the default: "s x m" in the least-significant bits,
+// and zeros in the extra most-significant ones.
+type Layout struct {
+	Order FieldOrder
+
+	// AlignMSB shifts the fields to the most-significant bits of uint16,
+	// so the extra bits are the least-significant ones.
+	AlignMSB bool
+}
+
+// String returns the layout as in a spec, such as "xms" or "sxm.msb".
+func (l Layout) String() string {
+	if l.AlignMSB {
+		return l.Order.String() + ".msb"
+	}
+	return l.Order.String()
+}
+
+// WithLayout selects the order and the alignment of the fields,
+// to read codes of other systems.
+// Encode and Decode work with codes in this layout,
+// and so do all functions that take or return codes.
+// The comparable form does not depend on the layout.
+//
+// Two's complement needs the sign field first, so types made
+// WithTwosComplement accept only OrderSXM, aligned either way.
+func WithLayout(layout Layout) Option {
+	return func(p *Params) {
+		p.Layout = layout
+	}
+}
+
+// Layout returns the layout of the codes of the type.
+func (t *Type) Layout() Layout {
+	return t.layout
+}
+
+// ----------------
+
+// normalizeLayout keeps a single order for unsigned types.
+func normalizeLayout(layout Layout, signed bool) Layout {
+	if signed {
+		return layout
+	}
+
+	switch layout.Order {
+	case OrderXSM, OrderXMS:
+		layout.Order = OrderSXM
+	case OrderMSX, OrderMXS:
+		layout.Order = OrderSMX
+	}
+	return layout
+}
+
+func setLayout(s *Type, layout Layout) error {
+	if int(layout.Order) >= len(fieldOrderNames) {
+		return ErrInvalidLayout
+	} else if s.twos && (OrderSXM != layout.Order) {
+		return ErrInvalidLayout
+	}
+
+	s.layout = layout
+	s.rearranged = (OrderSXM != layout.Order) || layout.AlignMSB
+	if layout.AlignMSB {
+		s.align = 16 - s.length
+	}
+
+	// From the least-significant field.
+	order := layout.Order.String()
+	shift := uint8(0)
+	for i := len(order) - 1; i >= 0; i-- {
+		switch order[i] {
+		case 's':
+			s.sShift = shift
+			if 0 != s.minus {
+				shift++
+			}
+		case 'x':
+			s.xShift = shift
+			shift += s.xSize
+		case 'm':
+			s.mShift = shift
+			shift += s.mSize
+		}
+	}
+	return nil
+}
+
+// fromLayout converts a code to sign–magnitude "s x m".
+// Codes in other layouts lose extra bits.
+func fromLayout(x uint16, t *Type) uint16 {
+	if !t.twos && !t.rearranged {
+		return x
+	}
+
+	x = fromPhysical(x, t)
+	if t.twos {
+		return twosToSignMagnitude(x&t.bitmask, t)
+	}
+	return x
+}
+
+// toLayout expects a sign–magnitude "s x m" code without extra bits.
+func toLayout(x uint16, t *Type) uint16 {
+	if !t.twos && !t.rearranged {
+		return x
+	}
+
+	if t.twos {
+		x = signMagnitudeToTwos(x, t)
+	}
+	return toPhysical(x, t)
+}
+
+// fromPhysical moves the fields to "s x m" and drops extra bits.
+func fromPhysical(x uint16, t *Type) uint16 {
+	if !t.rearranged {
+		return x
+	}
+
+	x = (x >> t.align) & t.bitmask
+	r := ((x>>t.xShift)&t.xMask)<<t.mSize | (x>>t.mShift)&t.mMask
+	if (0 != t.minus) && (0 != (x>>t.sShift)&1) {
+		r |= t.minus
+	}
+	return r
+}
+
+// toPhysical is fromPhysical in reverse.
+func toPhysical(x uint16, t *Type) uint16 {
+	if !t.rearranged {
+		return x
+	}
+
+	x &= t.bitmask
+	r := ((x>>t.mSize)&t.xMask)<<t.xShift | (x&t.mMask)<<t.mShift
+	if isNegative(x, t.minus) {
+		r |= uint16(1) << t.sShift
+	}
+	return r << t.align
+}
@@ -0,0 +1,314 @@
+package toyfloat
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// rearrange moves the fields of a default code by their names.
+func rearrange(code uint16, plain *Type, layout Layout) uint16 {
+	sizes := map[byte]uint8{'s': 0, 'x': plain.xSize, 'm': plain.mSize}
+	fields := map[byte]uint16{
+		'x': (code >> plain.mSize) & plain.xMask,
+		'm': code & plain.mMask,
+	}
+
+	if 0 != plain.minus {
+		sizes['s'] = 1
+		fields['s'] = code >> (plain.length - 1) & 1
+	}
+
+	r := uint16(0)
+	for _, name := range []byte(layout.Order.String()) {
+		r = r<<sizes[name] | fields[name]
+	}
+
+	if layout.AlignMSB {
+		r <<= 16 - plain.length
+	}
+	return r
+}
+
+func allLayouts() []Layout {
+	var layouts []Layout
+	for order := range fieldOrderNames {
+		for _, msb := range []bool{false, true} {
+			layouts = append(layouts, Layout{Order: FieldOrder(order), AlignMSB: msb})
+		}
+	}
+	return layouts
+}
+
+func TestLayoutEncoding(t *testing.T) {
+	for _, signed := range []bool{true, false} {
+		plain := makeTypeX4(12, signed, t)
+
+		for _, layout := range allLayouts() {
+			tf, err := NewTypeX4(12, signed, WithLayout(layout))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for f := -300.0; f <= 300.0; f += 0.01 {
+				code := tf.Encode(f)
+				expected := rearrange(plain.Encode(f), &plain, layout)
+				if code != expected {
+					t.Fatalf("%s: %f -> 0x%X, expected 0x%X", tf.Spec(), f, code, expected)
+				}
+			}
+
+			for i := 0; i <= int(plain.bitmask); i++ {
+				code := rearrange(uint16(i), &plain, layout)
+				if v := tf.Decode(code); v != plain.Decode(uint16(i)) {
+					t.Fatalf("%s: 0x%X -> %f", tf.Spec(), code, v)
+				}
+
+				// The extra bits are the other ones.
+				extra := ^(plain.bitmask << tf.align)
+				if v := tf.Decode(code | extra); v != plain.Decode(uint16(i)) {
+					t.Fatalf("%s: 0x%X -> %f", tf.Spec(), code|extra, v)
+				}
+
+				if c := tf.ToComparable(code); c != plain.ToComparable(uint16(i)) {
+					t.Fatalf("%s: 0x%X -> comparable 0x%X", tf.Spec(), code, c)
+				}
+
+				back := tf.FromComparable(plain.ToComparable(uint16(i)))
+				if back&(plain.bitmask<<tf.align) != code {
+					t.Fatalf("%s: 0x%X -> 0x%X", tf.Spec(), code, back)
+				}
+
+				abs := rearrange(plain.Abs(uint16(i)), &plain, layout)
+				if tf.Abs(code) != abs {
+					t.Fatalf("%s: Abs(0x%X) = 0x%X", tf.Spec(), code, tf.Abs(code))
+				}
+			}
+		}
+	}
+}
+
+func TestLayoutCodes(t *testing.T) {
+	tests := []struct {
+		layout Layout
+		codes  [3]uint16 // -1, 1.5, -0.25
+	}{
+		{Layout{}, [3]uint16{0xC00, 0x440, 0xB01}},
+		{Layout{AlignMSB: true}, [3]uint16{0xC000, 0x4400, 0xB010}},
+		{Layout{Order: OrderXMS}, [3]uint16{0x801, 0x880, 0x603}},
+		{Layout{Order: OrderXMS, AlignMSB: true}, [3]uint16{0x8010, 0x8800, 0x6030}},
+		{Layout{Order: OrderMXS}, [3]uint16{0x11, 0x810, 0x2D}},
+	}
+
+	for _, tt := range tests {
+		tf, err := NewTypeX4(12, true, WithLayout(tt.layout))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, v := range []float64{-1, 1.5, -0.25} {
+			if code := tf.Encode(v); code != tt.codes[i] {
+				t.Fatalf("%s: %f -> 0x%X, expected 0x%X", tt.layout, v, code, tt.codes[i])
+			}
+		}
+	}
+}
+
+func TestLayoutFastPath(t *testing.T) {
+	for _, signed := range []bool{true, false} {
+		tf := makeTypeX4(12, signed, t)
+		if tf.rearranged || !tf.plain {
+			t.Fatalf("the default layout must use the fast path")
+		}
+
+		// The same type without the fast paths.
+		generic := tf
+		generic.rearranged = true
+		generic.plain = false
+
+		for f := -300.0; f <= 300.0; f += 0.01 {
+			if tf.Encode(f) != generic.Encode(f) {
+				t.Fatalf("%f: 0x%X != 0x%X", f, tf.Encode(f), generic.Encode(f))
+			}
+		}
+
+		for i := 0; i <= int(tf.bitmask); i++ {
+			code := uint16(i)
+			if tf.Decode(code) != generic.Decode(code) {
+				t.Fatalf("0x%X: %f != %f", code, tf.Decode(code), generic.Decode(code))
+			} else if tf.ToComparable(code) != generic.ToComparable(code) {
+				t.Fatalf("0x%X: comparable forms differ", code)
+			}
+
+			last := tf.Encode(1)
+			delta := tf.GetIntegerDelta(last, code)
+			if delta != generic.GetIntegerDelta(last, code) {
+				t.Fatalf("0x%X: deltas differ", code)
+			}
+
+			// The fast path does not reset extra bits.
+			a := tf.UseIntegerDelta(last, delta) & tf.bitmask
+			if b := generic.UseIntegerDelta(last, delta); a != b {
+				t.Fatalf("0x%X: 0x%X != 0x%X", code, a, b)
+			}
+		}
+	}
+}
+
+func TestLayoutWithOptions(t *testing.T) {
+	plain := makeTypeX4(12, true, t)
+
+	tf, err := NewTypeX4(12, true, WithLayout(Layout{Order: OrderXMS}),
+		WithNonFinite(), WithReserved(0x803))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !math.IsInf(tf.Decode(tf.Encode(math.Inf(-1))), -1) {
+		t.Fatalf("-Inf expected")
+	}
+
+	// 0x803 is 0xC01 in the default layout.
+	if !tf.IsReserved(0x803) || !math.IsNaN(tf.Decode(0x803)) {
+		t.Fatalf("0x803 must be reserved")
+	}
+
+	if code := tf.Encode(plain.Decode(0xC01)); code == 0x803 {
+		t.Fatalf("reserved code encoded")
+	}
+
+	// Codes are converted between layouts.
+	xms, err := NewTypeX4(12, true, WithLayout(Layout{Order: OrderXMS}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wide := makeTypeX4(16, true, t)
+	for f := -255.0; f <= 255.0; f += 0.1 {
+		widened, err := Widen(xms.Encode(f), &xms, &wide)
+		if err != nil {
+			t.Fatal(err)
+		} else if wide.Decode(widened) != xms.Decode(xms.Encode(f)) {
+			t.Fatalf("%f: 0x%X", f, widened)
+		}
+	}
+
+	twos, err := NewTypeX4(12, true, WithTwosComplement(),
+		WithLayout(Layout{AlignMSB: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last := twos.Encode(twos.MinValue())
+	for f := -255.0; f <= 255.0; f += 0.01 {
+		code := twos.Encode(f)
+		if 0 != code&0xF {
+			t.Fatalf("%f -> 0x%X has extra bits", f, code)
+		} else if int16(code) < int16(last) {
+			t.Fatalf("%f: %d < %d", f, int16(code), int16(last))
+		}
+		last = code
+	}
+
+	codes := []uint16{tf.Encode(3), tf.Encode(-2), tf.Encode(-100)}
+	SortCodes(&tf, codes)
+	if tf.Decode(codes[0]) != tf.Decode(tf.Encode(-100)) {
+		t.Fatalf("wrong order %X", codes)
+	}
+
+	if Sum(&tf, codes) != tf.Decode(codes[0])+tf.Decode(codes[1])+tf.Decode(codes[2]) {
+		t.Fatalf("Sum: %f", Sum(&tf, codes))
+	}
+}
+
+func TestLayoutParams(t *testing.T) {
+	for _, layout := range allLayouts() {
+		tf, err := NewTypeX4(12, true, WithLayout(layout))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tf.Layout() != layout {
+			t.Fatalf("%s != %s", tf.Layout(), layout)
+		}
+
+		if (Layout{}) == layout {
+			if strings.Contains(tf.Spec(), "+layout") {
+				t.Fatalf("%s: the default layout is not written", tf.Spec())
+			}
+		} else if !strings.HasSuffix(tf.Spec(), "+layout="+layout.String()) {
+			t.Fatalf("%s: no layout %s", tf.Spec(), layout)
+		}
+
+		parsed, err := ParseType(tf.Spec())
+		if err != nil {
+			t.Fatal(err)
+		} else if parsed != tf {
+			t.Fatalf("%s: different types", tf.Spec())
+		}
+	}
+
+	// Unsigned types have no sign field.
+	xms, err := NewTypeX4(12, false, WithLayout(Layout{Order: OrderXMS}))
+	if err != nil {
+		t.Fatal(err)
+	} else if xms != makeTypeX4(12, false, t) {
+		t.Fatalf("xms must be the default for unsigned types")
+	}
+
+	_, err = NewTypeX4(12, true, WithTwosComplement(),
+		WithLayout(Layout{Order: OrderXMS}))
+	if !errors.Is(err, ErrInvalidLayout) {
+		t.Fatalf("ErrInvalidLayout expected, got %v", err)
+	}
+
+	_, err = NewTypeX4(12, true, WithLayout(Layout{Order: OrderMXS + 1}))
+	if !errors.Is(err, ErrInvalidLayout) {
+		t.Fatalf("ErrInvalidLayout expected, got %v", err)
+	}
+
+	_, err = NewTypeX4(12, true, WithLayout(Layout{AlignMSB: true}),
+		WithReserved(0x7FF))
+	if !errors.Is(err, ErrInvalidReservedCode) {
+		t.Fatalf("ErrInvalidReservedCode expected, got %v", err)
+	}
+
+	for _, bad := range []string{
+		"s12x4b2m-8+layout=sxm",
+		"s12x4b2m-8+layout=",
+		"s12x4b2m-8+layout=msb",
+		"s12x4b2m-8+layout=xyz.msb"} {
+
+		if _, err := ParseType(bad); err == nil {
+			t.Fatalf("%s: error expected", bad)
+		}
+	}
+}
+
+func BenchmarkEncodeLayout(b *testing.B) {
+	toyfloat12, e := NewTypeX4(12, true, WithLayout(Layout{Order: OrderXMS}))
+	if e != nil {
+		b.Fatal(e)
+	}
+
+	r := uint16(0)
+	const scale = 256.0 / 10000
+	for i := 0; i < b.N; i++ {
+		r = toyfloat12.Encode(scale * float64(i%10000))
+	}
+	intResult = int(r)
+}
+
+func BenchmarkDecodeLayout(b *testing.B) {
+	toyfloat12, e := NewTypeX4(12, true, WithLayout(Layout{Order: OrderXMS}))
+	if e != nil {
+		b.Fatal(e)
+	}
+
+	r := 0.0
+	for i := 0; i < b.N; i++ {
+		r = toyfloat12.Decode(uint16(i))
+	}
+	intResult = int(r)
+}
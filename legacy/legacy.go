@@ -0,0 +1,181 @@
+// Package legacy reads and writes codes of the presets,
+// that were removed or renamed before version 1.6,
+// when types became customizable.
+//
+// The renamed presets are the same formats as today:
+//
+//	defaultD  12-bit with 4-bit exponent, now "12" (1.2–1.4)
+//	14d       14-bit with 4-bit exponent, now "14" (1.2–1.4)
+//	m11x3d    15-bit with 3-bit exponent, now "15x3" (1.2–1.4)
+//	unsigned  12-bit unsigned, now "12u" (up to 1.4)
+//	12u       the same (1.5)
+//
+// The removed presets had the same exponents and lengths,
+// but the exponent in the most-significant bits, then the sign,
+// then the mantissa. Version 1.2 moved the sign to the top
+// for the delta encoding:
+//
+//	Default   ____ xxxx smmm mmmm (up to 1.4)
+//	14        __xx xxsm mmmm mmmm (up to 1.4)
+//	m11x3     _xxx smmm mmmm mmmm (1.1–1.4)
+//
+// Lookup finds all of them by these names.
+//
+// Before version 1.2, encoders did not round the mantissa,
+// but truncated it, so the values were rounded towards zero.
+// LookupTruncated makes such formats. Decoding was the same.
+package legacy
+
+import (
+	"errors"
+	"math"
+
+	"github.com/georgy7/toyfloat"
+)
+
+// ErrUnknownFormat is returned for names of presets
+// that this package does not know.
+var ErrUnknownFormat = errors.New("unknown legacy format")
+
+// Format is a historical preset.
+type Format struct {
+	name      string
+	t         toyfloat.Type
+	current   toyfloat.Type
+	truncated bool
+}
+
+// Lookup returns one of the presets, as versions 1.2–1.5 encoded them:
+// "Default", "14", "m11x3", "defaultD", "14d", "m11x3d", "unsigned" or "12u".
+func Lookup(name string) (Format, error) {
+	p, ok := presets[name]
+	if !ok {
+		return Format{}, ErrUnknownFormat
+	}
+	return newFormat(name, p, false)
+}
+
+// LookupTruncated returns one of the presets of versions before 1.2:
+// "Default", "14", "m11x3" or "unsigned", with the encoder,
+// that did not round the mantissa.
+func LookupTruncated(name string) (Format, error) {
+	p, ok := presets[name]
+	if !ok || !p.truncated {
+		return Format{}, ErrUnknownFormat
+	}
+	return newFormat(name, p, true)
+}
+
+// Name returns the historical name of the format.
+func (f *Format) Name() string {
+	return f.name
+}
+
+// Truncated tells, if the encoder does not round the mantissa.
+func (f *Format) Truncated() bool {
+	return f.truncated
+}
+
+// Type returns the format as a type, with the historical order of the fields.
+// Its Encode rounds the mantissa, even if the format is truncated.
+func (f *Format) Type() toyfloat.Type {
+	return f.t
+}
+
+// Current returns the preset of today, which has the same values,
+// such as toyfloat.NewTypeX4(12, true) for "Default".
+func (f *Format) Current() toyfloat.Type {
+	return f.current
+}
+
+// Encode returns the code of the value,
+// as the historical version of the format did.
+func (f *Format) Encode(v float64) uint16 {
+	code := f.t.Encode(v)
+	if !f.truncated || math.IsNaN(v) {
+		return code
+	}
+
+	// The largest magnitude, that is not above the value.
+	// Decode snaps values to 1 the same way.
+	decoded := f.t.Decode(code)
+	if math.Abs(decoded)-math.Abs(v) <= 1e-14*math.Abs(v) {
+		return code
+	}
+
+	x := f.t.ToSignMagnitude(code)
+	if 0 == x&f.magnitudeMask() {
+		return code
+	}
+	return f.t.FromSignMagnitude(x - 1)
+}
+
+// Decode returns the value of the code.
+func (f *Format) Decode(code uint16) float64 {
+	return f.t.Decode(code)
+}
+
+// ToCurrent converts a code of the format to the code
+// of the same value of the current preset.
+func (f *Format) ToCurrent(code uint16) uint16 {
+	return f.current.FromSignMagnitude(f.t.ToSignMagnitude(code))
+}
+
+// FromCurrent is ToCurrent in reverse.
+func (f *Format) FromCurrent(code uint16) uint16 {
+	return f.t.FromSignMagnitude(f.current.ToSignMagnitude(code))
+}
+
+// ----------------
+
+type preset struct {
+	params toyfloat.Params
+
+	// The order of the fields, which differs from today's.
+	order toyfloat.FieldOrder
+
+	// The preset existed before version 1.2.
+	truncated bool
+}
+
+var (
+	params12  = toyfloat.Params{Length: 12, XBase: 2, XSize: 4, MinX: -8, Signed: true}
+	params14  = toyfloat.Params{Length: 14, XBase: 2, XSize: 4, MinX: -8, Signed: true}
+	params15  = toyfloat.Params{Length: 15, XBase: 2, XSize: 3, MinX: -6, Signed: true}
+	params12u = toyfloat.Params{Length: 12, XBase: 2, XSize: 4, MinX: -8, Signed: false}
+)
+
+var presets = map[string]preset{
+	"Default":  {params12, toyfloat.OrderXSM, true},
+	"14":       {params14, toyfloat.OrderXSM, true},
+	"m11x3":    {params15, toyfloat.OrderXSM, true},
+	"defaultD": {params12, toyfloat.OrderSXM, false},
+	"14d":      {params14, toyfloat.OrderSXM, false},
+	"m11x3d":   {params15, toyfloat.OrderSXM, false},
+	"unsigned": {params12u, toyfloat.OrderSXM, true},
+	"12u":      {params12u, toyfloat.OrderSXM, false},
+}
+
+func newFormat(name string, p preset, truncated bool) (Format, error) {
+	current, err := toyfloat.FromParams(p.params)
+	if err != nil {
+		return Format{}, err
+	}
+
+	historical := p.params
+	historical.Layout = toyfloat.Layout{Order: p.order}
+	t, err := toyfloat.FromParams(historical)
+	if err != nil {
+		return Format{}, err
+	}
+
+	return Format{name: name, t: t, current: current, truncated: truncated}, nil
+}
+
+func (f *Format) magnitudeMask() uint16 {
+	p := f.t.Params()
+	if p.Signed {
+		return (uint16(1) << (p.Length - 1)) - 1
+	}
+	return (uint16(1) << p.Length) - 1
+}
@@ -0,0 +1,193 @@
+package legacy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/georgy7/toyfloat"
+)
+
+func TestLookup(t *testing.T) {
+	names := map[string]string{
+		"defaultD": "12", "14d": "14", "m11x3d": "15x3", "unsigned": "12u", "12u": "12u",
+	}
+
+	for name, preset := range names {
+		f, err := Lookup(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		current, ok := toyfloat.Lookup(preset)
+		if !ok {
+			t.Fatal(preset)
+		} else if f.Current() != current || f.Type() != current || f.Name() != name {
+			t.Fatalf("%s: %+v", name, current.Params())
+		}
+
+		for i := 0; i < 1<<current.Params().Length; i++ {
+			code := uint16(i)
+			if f.ToCurrent(code) != code || f.FromCurrent(code) != code {
+				t.Fatalf("%s: 0x%X", name, code)
+			}
+		}
+
+		for _, v := range []float64{-2.7, -1, -0.001, 0, 0.3, 1, 100} {
+			if f.Encode(v) != current.Encode(v) {
+				t.Fatalf("%s: %v", name, v)
+			}
+		}
+	}
+
+	for _, name := range []string{"12", "default", "Default14"} {
+		if _, err := Lookup(name); err != ErrUnknownFormat {
+			t.Fatalf("%s: ErrUnknownFormat expected, got %v", name, err)
+		}
+	}
+}
+
+func TestRemoved(t *testing.T) {
+	names := map[string]string{"Default": "12", "14": "14", "m11x3": "15x3"}
+
+	for name, preset := range names {
+		f, err := Lookup(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		current, _ := toyfloat.Lookup(preset)
+		if f.Current() != current || f.Type() == current || f.Truncated() {
+			t.Fatalf("%s: %s", name, f.Name())
+		}
+
+		tf := f.Type()
+		for i := 0; i < 1<<tf.Params().Length; i++ {
+			code := uint16(i)
+			c := f.ToCurrent(code)
+
+			if f.FromCurrent(c) != code {
+				t.Fatalf("%s: 0x%X -> 0x%X", name, code, c)
+			} else if v, w := f.Decode(code), current.Decode(c); v != w {
+				t.Fatalf("%s: 0x%X: %v != %v", name, code, v, w)
+			}
+		}
+	}
+}
+
+// The codes of versions 1.0–1.4, with the exponent in the top bits.
+func TestHistoricalCodes(t *testing.T) {
+	cases := []struct {
+		name      string
+		v         float64
+		rounded   uint16
+		truncated uint16
+	}{
+		{"Default", 1, 0x800, 0x800},
+		{"Default", -1, 0x880, 0x880},
+		{"Default", 0.3, 0x61B, 0x61B},
+		{"Default", -2.7, 0x9AC, 0x9AC},
+		{"Default", 100, 0xE47, 0xE47},
+		{"Default", 0.001, 0x021, 0x020},
+		{"14", 1, 0x2000, 0x2000},
+		{"14", -1, 0x2200, 0x2200},
+		{"14", -2.7, 0x26B1, 0x26B1},
+		{"14", 100, 0x391D, 0x391C},
+		{"14", 0.001, 0x0083, 0x0082},
+		{"m11x3", 1, 0x6000, 0x6000},
+		{"m11x3", -1, 0x6800, 0x6800},
+		{"m11x3", 0.3, 0x41F3, 0x41F3},
+		{"m11x3", -2.7, 0x7AB2, 0x7AB1},
+		{"m11x3", 100, 0x77FF, 0x77FF},
+		{"unsigned", 1, 0x800, 0x800},
+		{"unsigned", 0.3, 0x636, 0x636},
+		{"unsigned", -2.7, 0x000, 0x000},
+	}
+
+	for _, c := range cases {
+		rounded, err := Lookup(c.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		truncated, err := LookupTruncated(c.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if code := rounded.Encode(c.v); code != c.rounded {
+			t.Fatalf("%s: %v: 0x%X", c.name, c.v, code)
+		} else if code := truncated.Encode(c.v); code != c.truncated {
+			t.Fatalf("%s: %v: 0x%X, truncated", c.name, c.v, code)
+		} else if rounded.Decode(c.truncated) != truncated.Decode(c.truncated) {
+			t.Fatalf("%s: 0x%X", c.name, c.truncated)
+		}
+	}
+
+	// The same values in the fields of today.
+	f, _ := Lookup("Default")
+	if f.ToCurrent(0x880) != 0xC00 || f.FromCurrent(0xCAC) != 0x9AC {
+		t.Fatalf("0x%X, 0x%X", f.ToCurrent(0x880), f.FromCurrent(0xCAC))
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	f, err := LookupTruncated("Default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rounded, err := Lookup("Default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tf := f.Type()
+	maxValue := f.Decode(f.Encode(math.Inf(+1)))
+
+	for v := -300.0; v < 300; v += 0.0137 {
+		code := f.Encode(v)
+		decoded := f.Decode(code)
+
+		if math.Abs(v) >= maxValue {
+			if code != rounded.Encode(v) {
+				t.Fatalf("%v: 0x%X", v, code)
+			}
+			continue
+		}
+
+		// Towards zero, and the next magnitude is above.
+		x := tf.ToSignMagnitude(code)
+		next := f.Decode(tf.FromSignMagnitude(x + 1))
+		if math.Abs(decoded) > math.Abs(v) || math.Abs(next) <= math.Abs(v) {
+			t.Fatalf("%v: %v, %v", v, decoded, next)
+		}
+	}
+
+	// The values of the codes stay.
+	for i := 0; i < 1<<12; i++ {
+		if v := f.Decode(uint16(i)); f.Encode(v) != rounded.Encode(v) {
+			t.Fatalf("0x%X: %v", i, v)
+		}
+	}
+
+	if !f.Truncated() || rounded.Truncated() || f.Name() != "Default" {
+		t.Fatal(f.Name())
+	}
+
+	// Presets of 1.2 and later rounded.
+	for _, name := range []string{"defaultD", "14d", "m11x3d", "12u", "12"} {
+		if _, err := LookupTruncated(name); err != ErrUnknownFormat {
+			t.Fatalf("%s: ErrUnknownFormat expected, got %v", name, err)
+		}
+	}
+}
+
+func BenchmarkEncodeTruncated(b *testing.B) {
+	f, _ := LookupTruncated("Default")
+
+	r := uint16(0)
+	for i := 0; i < b.N; i++ {
+		r += f.Encode(float64(i&1023) * 0.173)
+	}
+	intResult = int(r)
+}
+
+var intResult int
@@ -0,0 +1,431 @@
+package toyfloat
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Marshal packs the exported fields of a struct into bits,
+// in the order of declaration, from the most-significant bit
+// of the first byte. The last byte is padded with zeros.
+//
+// Float fields must have a tag with their type, which is one of:
+//
+//	`toyfloat:"x4,12,signed"`  NewTypeX4(12, true); "x2" and "x3" also work,
+//	                          and "unsigned" is the default
+//	`toyfloat:"15x3"`          a name from the default registry, see Lookup
+//	`toyfloat:"s12x4b2m-8"`    a spec, see ParseType
+//
+// Only the bits of the values are stored, so extra bits of codes,
+// such as tags or check bits, are dropped.
+//
+// Bools take one bit. Integers take as many bits as their Go type,
+// or as the tag says, for example `toyfloat:"5"`.
+// The tag is required for int, uint and uintptr, whose size depends
+// on the platform, so that records are the same everywhere.
+// Signed integers are stored in two's complement, and ErrValueOutOfRange
+// is returned if a value does not fit.
+// Nested structs and arrays are packed field by field, and an array
+// applies its tag to each element. Fields tagged `toyfloat:"-"` are skipped.
+func Marshal(v interface{}) ([]byte, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%T: %w", v, ErrUnsupportedField)
+	}
+
+	plan, err := planFor(value.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	w := bitWriter{data: make([]byte, 0, (plan.bits+7)/8)}
+	if err := marshalValue(&w, value, &plan.root); err != nil {
+		return nil, err
+	}
+	return w.data, nil
+}
+
+// Unmarshal is Marshal in reverse. The argument must be
+// a non-nil pointer to a struct, and the record must be as long
+// as Marshal makes it for this struct, or ErrRecordLength is returned.
+func Unmarshal(data []byte, v interface{}) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() ||
+		value.Elem().Kind() != reflect.Struct {
+
+		return fmt.Errorf("%T: %w", v, ErrUnsupportedField)
+	}
+
+	value = value.Elem()
+	plan, err := planFor(value.Type())
+	if err != nil {
+		return err
+	}
+
+	if len(data) != (plan.bits+7)/8 {
+		return fmt.Errorf("%d bytes for %s: %w", len(data), value.Type(), ErrRecordLength)
+	}
+
+	r := bitReader{data: data}
+	unmarshalValue(&r, value, &plan.root)
+	return nil
+}
+
+// ----------------
+
+type recordKind uint8
+
+const (
+	floatField recordKind = iota
+	intField
+	uintField
+	boolField
+	structField
+	arrayField
+)
+
+// recordField is a field, an array element or the struct itself.
+type recordField struct {
+	name   string
+	kind   recordKind
+	index  int // of the struct field
+	bits   uint8
+	tf     Type
+	fields []recordField // of a struct, or the element of an array
+	length int           // of an array
+}
+
+type recordPlan struct {
+	root recordField
+	bits int
+}
+
+type planResult struct {
+	plan *recordPlan
+	err  error
+}
+
+var recordPlans sync.Map // reflect.Type -> planResult
+
+func planFor(t reflect.Type) (*recordPlan, error) {
+	if cached, ok := recordPlans.Load(t); ok {
+		r := cached.(planResult)
+		return r.plan, r.err
+	}
+
+	plan := &recordPlan{}
+	root, err := planStruct(t, t.Name(), &plan.bits)
+	if err != nil {
+		plan = nil
+	} else {
+		plan.root = root
+	}
+
+	recordPlans.Store(t, planResult{plan, err})
+	return plan, err
+}
+
+func planStruct(t reflect.Type, name string, bits *int) (recordField, error) {
+	r := recordField{name: name, kind: structField}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup("toyfloat")
+		if (sf.PkgPath != "") || (tag == "-") {
+			// Unexported or skipped.
+			continue
+		}
+
+		f, err := planField(sf.Type, name+"."+sf.Name, tag, tagged, bits)
+		if err != nil {
+			return recordField{}, err
+		}
+
+		f.index = i
+		r.fields = append(r.fields, f)
+	}
+	return r, nil
+}
+
+func planField(t reflect.Type, name, tag string, tagged bool,
+	bits *int) (recordField, error) {
+
+	f := recordField{name: name}
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if !tagged {
+			return f, fmt.Errorf("field %s has no type: %w", name, ErrInvalidTag)
+		}
+
+		tf, err := ParseTag(tag)
+		if err != nil {
+			return f, fmt.Errorf("field %s, tag %q: %w", name, tag, err)
+		}
+
+		f.kind = floatField
+		f.tf = tf
+		f.bits = tf.length
+
+	case reflect.Bool:
+		if tagged {
+			return f, fmt.Errorf("field %s: %w", name, ErrInvalidTag)
+		}
+		f.kind = boolField
+		f.bits = 1
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+
+		f.kind = intField
+		if t.Kind() >= reflect.Uint {
+			f.kind = uintField
+		}
+
+		// The size of int, uint and uintptr depends on the platform.
+		size := t.Bits()
+		sized := (reflect.Int != t.Kind()) && (reflect.Uint != t.Kind()) &&
+			(reflect.Uintptr != t.Kind())
+		if !sized {
+			size = 64
+		}
+
+		if tagged {
+			n, err := strconv.Atoi(tag)
+			if (err != nil) || (n < 1) || (n > size) {
+				return f, fmt.Errorf("field %s, tag %q: %w", name, tag, ErrInvalidTag)
+			}
+			size = n
+		} else if !sized {
+			return f, fmt.Errorf("field %s of type %s needs its size in the tag: %w",
+				name, t, ErrInvalidTag)
+		}
+		f.bits = uint8(size)
+
+	case reflect.Struct:
+		if tagged {
+			return f, fmt.Errorf("field %s: %w", name, ErrInvalidTag)
+		}
+
+		r, err := planStruct(t, name, bits)
+		if err != nil {
+			return f, err
+		}
+		return r, nil
+
+	case reflect.Array:
+		element, err := planField(t.Elem(), name+"[]", tag, tagged, bits)
+		if err != nil {
+			return f, err
+		}
+
+		// The element counted itself once.
+		*bits += (t.Len() - 1) * elementBits(&element)
+
+		f.kind = arrayField
+		f.fields = []recordField{element}
+		f.length = t.Len()
+		return f, nil
+
+	default:
+		return f, fmt.Errorf("field %s of type %s: %w", name, t, ErrUnsupportedField)
+	}
+
+	*bits += int(f.bits)
+	return f, nil
+}
+
+// elementBits counts the bits of a planned field.
+func elementBits(f *recordField) int {
+	switch f.kind {
+	case structField:
+		n := 0
+		for i := range f.fields {
+			n += elementBits(&f.fields[i])
+		}
+		return n
+	case arrayField:
+		return f.length * elementBits(&f.fields[0])
+	}
+	return int(f.bits)
+}
+
+// ParseTag makes the type of a float field from its tag,
+// which is "x4,12,signed", a registered name or a spec, as Marshal reads it.
+func ParseTag(tag string) (Type, error) {
+	if !strings.Contains(tag, ",") {
+		if t, ok := Lookup(tag); ok {
+			return t, nil
+		}
+		return ParseType(tag)
+	}
+
+	parts := strings.Split(tag, ",")
+	if len(parts) > 3 {
+		return Type{}, ErrInvalidTag
+	}
+
+	length, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Type{}, ErrInvalidTag
+	}
+
+	signed := false
+	if len(parts) == 3 {
+		switch parts[2] {
+		case "signed":
+			signed = true
+		case "unsigned":
+		default:
+			return Type{}, ErrInvalidTag
+		}
+	}
+
+	switch parts[0] {
+	case "x2":
+		return NewTypeX2(length, signed)
+	case "x3":
+		return NewTypeX3(length, signed)
+	case "x4":
+		return NewTypeX4(length, signed)
+	}
+	return Type{}, ErrInvalidTag
+}
+
+func marshalValue(w *bitWriter, v reflect.Value, f *recordField) error {
+	switch f.kind {
+	case floatField:
+		w.write(uint64(f.tf.index(encode(v.Float(), &f.tf))), f.bits)
+
+	case intField:
+		x := v.Int()
+		if f.bits < 64 {
+			limit := int64(1) << (f.bits - 1)
+			if (x < -limit) || (x >= limit) {
+				return fmt.Errorf("field %s = %d: %w", f.name, x, ErrValueOutOfRange)
+			}
+		}
+		w.write(uint64(x), f.bits)
+
+	case uintField:
+		x := v.Uint()
+		if (f.bits < 64) && (0 != x>>f.bits) {
+			return fmt.Errorf("field %s = %d: %w", f.name, x, ErrValueOutOfRange)
+		}
+		w.write(x, f.bits)
+
+	case boolField:
+		if v.Bool() {
+			w.write(1, 1)
+		} else {
+			w.write(0, 1)
+		}
+
+	case structField:
+		for i := range f.fields {
+			field := &f.fields[i]
+			if err := marshalValue(w, v.Field(field.index), field); err != nil {
+				return err
+			}
+		}
+
+	case arrayField:
+		for i := 0; i < f.length; i++ {
+			if err := marshalValue(w, v.Index(i), &f.fields[0]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func unmarshalValue(r *bitReader, v reflect.Value, f *recordField) {
+	switch f.kind {
+	case floatField:
+		code := uint16(r.read(f.bits)) << f.tf.align
+		v.SetFloat(decode(code, &f.tf))
+
+	case intField:
+		x := r.read(f.bits)
+		if f.bits < 64 {
+			// Sign extension.
+			shift := 64 - f.bits
+			v.SetInt(int64(x<<shift) >> shift)
+		} else {
+			v.SetInt(int64(x))
+		}
+
+	case uintField:
+		v.SetUint(r.read(f.bits))
+
+	case boolField:
+		v.SetBool(1 == r.read(1))
+
+	case structField:
+		for i := range f.fields {
+			field := &f.fields[i]
+			unmarshalValue(r, v.Field(field.index), field)
+		}
+
+	case arrayField:
+		for i := 0; i < f.length; i++ {
+			unmarshalValue(r, v.Index(i), &f.fields[0])
+		}
+	}
+}
+
+// bitWriter appends bits from the most-significant one.
+type bitWriter struct {
+	data []byte
+	bits uint // used in the last byte
+}
+
+func (w *bitWriter) write(x uint64, n uint8) {
+	for n > 0 {
+		if 0 == w.bits%8 {
+			w.data = append(w.data, 0)
+			w.bits = 0
+		}
+
+		free := 8 - w.bits
+		take := uint(n)
+		if take > free {
+			take = free
+		}
+
+		chunk := byte((x >> (uint(n) - take)) & ((1 << take) - 1))
+		w.data[len(w.data)-1] |= chunk << (free - take)
+		w.bits += take
+		n -= uint8(take)
+	}
+}
+
+type bitReader struct {
+	data   []byte
+	offset uint // in bits
+}
+
+func (r *bitReader) read(n uint8) uint64 {
+	x := uint64(0)
+	for n > 0 {
+		b := r.data[r.offset/8]
+		used := r.offset % 8
+		free := 8 - used
+		take := uint(n)
+		if take > free {
+			take = free
+		}
+
+		chunk := (b >> (free - take)) & ((1 << take) - 1)
+		x = x<<take | uint64(chunk)
+		r.offset += take
+		n -= uint8(take)
+	}
+	return x
+}
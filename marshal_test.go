@@ -0,0 +1,206 @@
+package toyfloat
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type marshalPoint struct {
+	X, Y  float64 `toyfloat:"x4,12,signed"`
+	Speed float32 `toyfloat:"15x3"`
+	Alpha float64 `toyfloat:"s12x4b2m-8"`
+	Flag  bool
+	Count uint8  `toyfloat:"5"`
+	Shift int16  `toyfloat:"7"`
+	Note  string `toyfloat:"-"`
+	extra int
+}
+
+type marshalTrack struct {
+	ID     uint16
+	Points [3]marshalPoint
+	Gains  [2]float64 `toyfloat:"x3,5"`
+}
+
+func TestMarshal(t *testing.T) {
+	x4 := makeTypeX4(12, true, t)
+	x3 := makeTypeX3(15, true, t)
+
+	p := marshalPoint{
+		X: 1.5, Y: -20, Speed: 0.1, Alpha: 3,
+		Flag: true, Count: 17, Shift: -33,
+		Note: "skipped", extra: 5,
+	}
+
+	data, err := Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 12+12+15+12+1+5+7 = 64 bits.
+	var w bitWriter
+	w.write(uint64(x4.Encode(p.X)), 12)
+	w.write(uint64(x4.Encode(p.Y)), 12)
+	w.write(uint64(x3.Encode(float64(p.Speed))), 15)
+	w.write(uint64(x4.Encode(p.Alpha)), 12)
+	w.write(1, 1)
+	w.write(17, 5)
+	w.write(uint64(128-33), 7)
+
+	if !bytes.Equal(data, w.data) {
+		t.Fatalf("%X != %X", data, w.data)
+	}
+
+	var back marshalPoint
+	if err := Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := marshalPoint{
+		X:     x4.Decode(x4.Encode(p.X)),
+		Y:     x4.Decode(x4.Encode(p.Y)),
+		Speed: float32(x3.Decode(x3.Encode(float64(p.Speed)))),
+		Alpha: x4.Decode(x4.Encode(p.Alpha)),
+		Flag:  true, Count: 17, Shift: -33,
+	}
+
+	if back != expected {
+		t.Fatalf("%+v != %+v", back, expected)
+	}
+
+	// A pointer works the same way.
+	if data2, err := Marshal(&p); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(data, data2) {
+		t.Fatalf("%X != %X", data2, data)
+	}
+}
+
+func TestMarshalNested(t *testing.T) {
+	x3 := makeTypeX3(5, false, t)
+
+	var track marshalTrack
+	track.ID = 0xBEEF
+	for i := range track.Points {
+		track.Points[i].X = float64(i) - 1.3
+		track.Points[i].Count = uint8(i * 10)
+		track.Points[i].Shift = int16(-i)
+	}
+	track.Gains = [2]float64{0.5, 1.7}
+
+	data, err := Marshal(track)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 16 + 3*64 + 2*5 = 218 bits.
+	if len(data) != 28 {
+		t.Fatalf("%d bytes", len(data))
+	}
+
+	var back marshalTrack
+	if err := Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+
+	if back.ID != track.ID {
+		t.Fatalf("0x%X != 0x%X", back.ID, track.ID)
+	}
+
+	for i := range track.Points {
+		if back.Points[i].Count != track.Points[i].Count ||
+			back.Points[i].Shift != track.Points[i].Shift {
+
+			t.Fatalf("%+v != %+v", back.Points[i], track.Points[i])
+		}
+	}
+
+	for i, g := range track.Gains {
+		if back.Gains[i] != x3.Decode(x3.Encode(g)) {
+			t.Fatalf("%f: %f", g, back.Gains[i])
+		}
+	}
+
+	if err := Unmarshal(data[1:], &back); !errors.Is(err, ErrRecordLength) {
+		t.Fatalf("ErrRecordLength expected, got %v", err)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	var ranges = []interface{}{
+		struct {
+			A int8 `toyfloat:"4"`
+		}{8},
+		struct {
+			A int8 `toyfloat:"4"`
+		}{-9},
+		struct {
+			A uint `toyfloat:"3"`
+		}{8},
+	}
+
+	for _, v := range ranges {
+		if _, err := Marshal(v); !errors.Is(err, ErrValueOutOfRange) {
+			t.Fatalf("%+v: ErrValueOutOfRange expected, got %v", v, err)
+		}
+	}
+
+	var tags = []interface{}{
+		struct{ A float64 }{},
+		struct {
+			A float64 `toyfloat:"x5,12"`
+		}{},
+		struct {
+			A float64 `toyfloat:"x4,12,both"`
+		}{},
+		struct {
+			A uint8 `toyfloat:"9"`
+		}{},
+		struct {
+			A bool `toyfloat:"2"`
+		}{},
+		struct{ A int }{},
+		struct{ A uint }{},
+		struct{ A uintptr }{},
+		struct {
+			A int `toyfloat:"65"`
+		}{},
+	}
+
+	for _, v := range tags {
+		if _, err := Marshal(v); !errors.Is(err, ErrInvalidTag) {
+			t.Fatalf("%+v: ErrInvalidTag expected, got %v", v, err)
+		}
+	}
+
+	bad := struct {
+		A float64 `toyfloat:"x4,17,signed"`
+	}{}
+	if _, err := Marshal(bad); !errors.Is(err, ErrLengthTooLarge) {
+		t.Fatalf("ErrLengthTooLarge expected, got %v", err)
+	}
+
+	unsupported := struct{ A []int }{}
+	if _, err := Marshal(unsupported); !errors.Is(err, ErrUnsupportedField) {
+		t.Fatalf("ErrUnsupportedField expected, got %v", err)
+	} else if _, err := Marshal(5); !errors.Is(err, ErrUnsupportedField) {
+		t.Fatalf("ErrUnsupportedField expected, got %v", err)
+	}
+
+	var p marshalPoint
+	if err := Unmarshal(make([]byte, 8), p); !errors.Is(err, ErrUnsupportedField) {
+		t.Fatalf("ErrUnsupportedField expected, got %v", err)
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	p := marshalPoint{X: 1.5, Y: -20, Speed: 0.1, Count: 3}
+
+	r := 0
+	for i := 0; i < b.N; i++ {
+		data, _ := Marshal(&p)
+		r += len(data)
+	}
+	intResult = r
+}
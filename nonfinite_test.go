@@ -0,0 +1,214 @@
+package toyfloat
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNonFiniteEncoding(t *testing.T) {
+	tf := makeTypeX4(12, true, t, WithNonFinite())
+
+	if v := tf.Decode(tf.Encode(math.Inf(+1))); !math.IsInf(v, +1) {
+		t.Fatalf("+Inf -> %f", v)
+	}
+
+	if v := tf.Decode(tf.Encode(math.Inf(-1))); !math.IsInf(v, -1) {
+		t.Fatalf("-Inf -> %f", v)
+	}
+
+	if v := tf.Decode(tf.Encode(math.NaN())); !math.IsNaN(v) {
+		t.Fatalf("NaN -> %f", v)
+	}
+
+	if v := tf.Decode(tf.minus | tf.nanCode); !math.IsNaN(v) {
+		t.Fatalf("negative NaN -> %f", v)
+	}
+
+	if v := tf.Decode(0xF000 | tf.Encode(math.Inf(+1))); !math.IsInf(v, +1) {
+		t.Fatalf("extra bits are not ignored: %f", v)
+	}
+
+	plain := makeTypeX4(12, true, t)
+	for _, v := range []float64{0, 1, -1, 1.567} {
+		if tf.Encode(v) != plain.Encode(v) {
+			t.Fatalf("%f is encoded differently", v)
+		}
+	}
+
+	unsigned := makeTypeX4(12, false, t, WithNonFinite())
+
+	if v := unsigned.Decode(unsigned.Encode(math.Inf(+1))); !math.IsInf(v, +1) {
+		t.Fatalf("+Inf -> %f (unsigned)", v)
+	}
+
+	if v := unsigned.Decode(unsigned.Encode(math.NaN())); !math.IsNaN(v) {
+		t.Fatalf("NaN -> %f (unsigned)", v)
+	}
+
+	if v := unsigned.Decode(unsigned.Encode(math.Inf(-1))); v != 0 {
+		t.Fatalf("-Inf -> %f (unsigned)", v)
+	}
+}
+
+func TestNonFiniteMaxValue(t *testing.T) {
+	tf := makeTypeX4(12, true, t, WithNonFinite())
+	plain := makeTypeX4(12, true, t)
+
+	if tf.MaxValue() >= plain.MaxValue() {
+		t.Fatalf("%f >= %f", tf.MaxValue(), plain.MaxValue())
+	}
+
+	if tf.MinValue() != -tf.MaxValue() {
+		t.Fatalf("%f != %f", tf.MinValue(), -tf.MaxValue())
+	}
+
+	expected := plain.Decode(plain.infCode - 2)
+	if tf.MaxValue() != expected {
+		t.Fatalf("%f != %f", tf.MaxValue(), expected)
+	}
+
+	for _, v := range []float64{1e6, math.MaxFloat64, tf.MaxValue()} {
+		if r := tf.Decode(tf.Encode(v)); r != tf.MaxValue() {
+			t.Fatalf("%f -> %f (must be saturated)", v, r)
+		}
+
+		if r := tf.Decode(tf.Encode(-v)); r != tf.MinValue() {
+			t.Fatalf("%f -> %f (must be saturated)", -v, r)
+		}
+	}
+}
+
+func TestNonFiniteRoundTrip(t *testing.T) {
+	tf := makeTypeX4(10, true, t, WithNonFinite())
+
+	for i := 0; i <= int(tf.bitmask); i++ {
+		code := uint16(i)
+		v := tf.Decode(code)
+
+		if code == tf.minus {
+			continue // -0
+		} else if math.IsNaN(v) {
+			if code&^tf.minus != tf.nanCode {
+				t.Fatalf("0x%X is NaN", code)
+			}
+			continue
+		}
+
+		if r := tf.Encode(v); r != code {
+			t.Fatalf("0x%X -> %f -> 0x%X", code, v, r)
+		}
+	}
+}
+
+func TestNonFiniteOrdering(t *testing.T) {
+	tf := makeTypeX4(12, true, t, WithNonFinite())
+
+	lowest := tf.ToComparable(tf.Encode(math.Inf(-1)))
+	highest := tf.ToComparable(tf.Encode(math.Inf(+1)))
+
+	if lowest != 0 {
+		t.Fatalf("-Inf is 0x%X", lowest)
+	}
+
+	if highest != tf.bitmask {
+		t.Fatalf("+Inf is 0x%X", highest)
+	}
+
+	previous := math.Inf(-1)
+	for c := int(lowest) + 2; c < int(highest)-1; c++ {
+		v := tf.Decode(tf.FromComparable(uint16(c)))
+		if math.IsNaN(v) || math.IsInf(v, 0) || (v < previous) {
+			t.Fatalf("0x%X -> %f after %f", c, v, previous)
+		}
+		previous = v
+	}
+
+	codes := []uint16{
+		tf.Encode(math.Inf(+1)), tf.Encode(3), tf.Encode(math.Inf(-1))}
+	if Min(&tf, codes) != math.Inf(-1) || Max(&tf, codes) != math.Inf(+1) {
+		t.Fatalf("infinities must be the extremes")
+	}
+}
+
+func TestNonFiniteParams(t *testing.T) {
+	tf := makeTypeX4(12, true, t, WithNonFinite())
+
+	if tf == makeTypeX4(12, true, t) {
+		t.Fatalf("the option must make a different type")
+	}
+
+	if !tf.Params().NonFinite {
+		t.Fatalf("the option is lost")
+	}
+
+	if tf.Spec() != "s12x4b2m-8+nonfinite" {
+		t.Fatalf("spec: %s", tf.Spec())
+	}
+
+	same, err := ParseType(tf.Spec())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if same != tf {
+		t.Fatalf("different types")
+	}
+
+	for _, spec := range []string{"s12x4b2m-8+", "s12x4b2m-8+nan"} {
+		if _, err := ParseType(spec); err == nil {
+			t.Fatalf("%s: error expected", spec)
+		}
+	}
+
+	if _, err := NewType(2, 2, 0, -1, false, WithNonFinite()); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewType(1, 2, 0, -1, false, WithNonFinite())
+	if !errors.Is(err, ErrTooFewCodes) {
+		t.Fatalf("ErrTooFewCodes expected, got %v", err)
+	}
+}
+
+func TestNonFiniteWidening(t *testing.T) {
+	narrow := makeTypeX4(12, true, t, WithNonFinite())
+	wide := makeTypeX4(16, true, t, WithNonFinite())
+
+	if IsCompatible(&narrow, &wide) == false {
+		t.Fatalf("must be compatible")
+	}
+
+	plain := makeTypeX4(16, true, t)
+	if IsCompatible(&narrow, &plain) {
+		t.Fatalf("must not be compatible")
+	}
+
+	for _, v := range []float64{math.Inf(-1), math.Inf(+1), math.NaN(), 3} {
+		w, err := Widen(narrow.Encode(v), &narrow, &wide)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := wide.Decode(w)
+		expected := narrow.Decode(narrow.Encode(v))
+		if (r != expected) && !(math.IsNaN(r) && math.IsNaN(expected)) {
+			t.Fatalf("%f -> %f", expected, r)
+		}
+
+		n, err := Narrow(w, &wide, &narrow, RoundToNearest)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n != narrow.Encode(v) {
+			t.Fatalf("0x%X != 0x%X", n, narrow.Encode(v))
+		}
+	}
+
+	// The maximum finite value must not become NaN.
+	n, _ := Narrow(wide.Encode(wide.MaxValue()), &wide, &narrow, RoundToNearest)
+	if narrow.Decode(n) != narrow.MaxValue() {
+		t.Fatalf("%f != %f", narrow.Decode(n), narrow.MaxValue())
+	}
+}
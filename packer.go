@@ -0,0 +1,106 @@
+package toyfloat
+
+// Field is a place for values of a type in a packed word.
+// Offset is the position of its least-significant bit.
+// It takes as many bits as the type is long.
+type Field struct {
+	Type   Type
+	Offset uint8
+}
+
+// Packer keeps several values of different types in one word,
+// such as a 5-bit X3 and an 11-bit X4 in a uint16.
+// Only the bits of the values are packed: extra bits of codes,
+// including tags and check bits, are dropped.
+//
+// A Packer is a value: copies share the fields,
+// but each one has its own word.
+type Packer struct {
+	fields []Field
+	width  uint8
+	word   uint64
+}
+
+// NewPacker checks that the fields fit into a word of the width,
+// which is 16, 32 or 64 bits, and do not overlap.
+// The word of the packer is zero.
+func NewPacker(width int, fields ...Field) (Packer, error) {
+	if (width != 16) && (width != 32) && (width != 64) {
+		return Packer{}, ErrInvalidWidth
+	}
+
+	used := uint64(0)
+	for _, f := range fields {
+		if !f.Type.IsValid() {
+			return Packer{}, ErrInvalidType
+		} else if int(f.Offset)+int(f.Type.length) > width {
+			return Packer{}, ErrFieldOutOfRange
+		}
+
+		mask := fieldMask(&f)
+		if 0 != used&mask {
+			return Packer{}, ErrFieldsOverlap
+		}
+		used |= mask
+	}
+
+	return Packer{
+		fields: append([]Field(nil), fields...),
+		width:  uint8(width),
+	}, nil
+}
+
+// Len returns the number of fields.
+func (p *Packer) Len() int {
+	return len(p.fields)
+}
+
+// Set encodes the value into the field i.
+// It panics if there is no such field.
+func (p *Packer) Set(i int, value float64) {
+	f := &p.fields[i]
+	p.SetCode(i, encode(value, &f.Type))
+}
+
+// Get decodes the field i.
+// It panics if there is no such field.
+func (p *Packer) Get(i int) float64 {
+	return decode(p.Code(i), &p.fields[i].Type)
+}
+
+// SetCode stores a code of the type of the field i.
+// It panics if there is no such field.
+func (p *Packer) SetCode(i int, code uint16) {
+	f := &p.fields[i]
+	bits := uint64(f.Type.index(code)) << f.Offset
+	p.word = (p.word &^ fieldMask(f)) | bits
+}
+
+// Code returns the field i as a code of its type without extra bits.
+// It panics if there is no such field.
+func (p *Packer) Code(i int) uint16 {
+	f := &p.fields[i]
+	bits := uint16((p.word & fieldMask(f)) >> f.Offset)
+	return bits << f.Type.align
+}
+
+// Word returns the packed word. Bits above the width are zero,
+// so it can be converted to uint16 or uint32 without loss.
+// Bits that belong to no field are kept as they were loaded.
+func (p *Packer) Word() uint64 {
+	return p.word
+}
+
+// Load replaces the packed word. Bits above the width are dropped.
+func (p *Packer) Load(word uint64) {
+	if p.width < 64 {
+		word &= (uint64(1) << p.width) - 1
+	}
+	p.word = word
+}
+
+// ----------------
+
+func fieldMask(f *Field) uint64 {
+	return uint64(f.Type.bitmask) << f.Offset
+}
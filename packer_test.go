@@ -0,0 +1,171 @@
+package toyfloat
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPacker(t *testing.T) {
+	x3 := makeTypeX3(5, false, t)
+	x4 := makeTypeX4(11, true, t)
+
+	p, err := NewPacker(16, Field{x3, 11}, Field{x4, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Len() != 2 {
+		t.Fatalf("%d != 2", p.Len())
+	}
+
+	for a := 0.0; a <= 2.0; a += 0.01 {
+		for b := -200.0; b <= 200.0; b += 13.7 {
+			p.Set(0, a)
+			p.Set(1, b)
+
+			expected := uint64(x3.Encode(a))<<11 | uint64(x4.Encode(b))
+			if p.Word() != expected {
+				t.Fatalf("%f, %f: 0x%X != 0x%X", a, b, p.Word(), expected)
+			}
+
+			if p.Get(0) != x3.Decode(x3.Encode(a)) {
+				t.Fatalf("%f: %f", a, p.Get(0))
+			} else if p.Get(1) != x4.Decode(x4.Encode(b)) {
+				t.Fatalf("%f: %f", b, p.Get(1))
+			}
+		}
+	}
+
+	// Copies have their own words.
+	q := p
+	q.Set(0, 0)
+	if p.Get(0) == 0 {
+		t.Fatalf("the copy changed the original")
+	}
+
+	q.Load(0xFFFFF)
+	if q.Word() != 0xFFFF {
+		t.Fatalf("0x%X != 0xFFFF", q.Word())
+	}
+
+	if q.Code(0) != 0x1F || q.Code(1) != 0x7FF {
+		t.Fatalf("0x%X, 0x%X", q.Code(0), q.Code(1))
+	}
+}
+
+func TestPackerWideWords(t *testing.T) {
+	var fields []Field
+	for i := uint8(0); i < 8; i++ {
+		fields = append(fields, Field{makeTypeX2(8, true, t), i * 8})
+	}
+
+	p, err := NewPacker(64, fields...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range fields {
+		p.Set(i, -float64(i))
+	}
+
+	x2 := makeTypeX2(8, true, t)
+	for i := range fields {
+		if v := p.Get(i); v != x2.Decode(x2.Encode(-float64(i))) {
+			t.Fatalf("%d: %f", i, v)
+		}
+	}
+
+	// The highest field uses the sign bit of the word.
+	if 0 == p.Word()>>63 {
+		t.Fatalf("0x%X", p.Word())
+	}
+
+	// Unused bits are kept.
+	x4 := makeTypeX4(12, true, t)
+	p, err = NewPacker(32, Field{x4, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.Load(0xABCD000F)
+	p.Set(0, 1)
+	if p.Word() != 0xABCD0000|uint64(x4.Encode(1))<<4|0xF {
+		t.Fatalf("0x%X", p.Word())
+	}
+}
+
+func TestPackerCodes(t *testing.T) {
+	msb, err := NewTypeX4(12, true, WithLayout(Layout{AlignMSB: true}),
+		WithProtection(Parity))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	twos, err := NewTypeX4(12, true, WithTwosComplement())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewPacker(32, Field{msb, 0}, Field{twos, 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.Set(0, -3)
+	p.Set(1, -3)
+
+	if p.Word() != uint64(twos.Encode(-3)&0xFFF)<<12|uint64(msb.Encode(-3)>>4) {
+		t.Fatalf("0x%X", p.Word())
+	}
+
+	if p.Get(0) != msb.Decode(msb.Encode(-3)) || p.Get(1) != twos.Decode(twos.Encode(-3)) {
+		t.Fatalf("%f, %f", p.Get(0), p.Get(1))
+	}
+
+	p.SetCode(1, twos.Encode(2))
+	if p.Code(1) != twos.Encode(2)&0xFFF || p.Get(1) != twos.Decode(twos.Encode(2)) {
+		t.Fatalf("0x%X", p.Code(1))
+	}
+}
+
+func TestPackerErrors(t *testing.T) {
+	x4 := makeTypeX4(12, true, t)
+
+	tests := []struct {
+		width  int
+		fields []Field
+		err    error
+	}{
+		{8, []Field{{x4, 0}}, ErrInvalidWidth},
+		{24, []Field{{x4, 0}}, ErrInvalidWidth},
+		{16, []Field{{x4, 5}}, ErrFieldOutOfRange},
+		{16, []Field{{x4, 0}, {x4, 4}}, ErrFieldsOverlap},
+		{32, []Field{{x4, 20}, {x4, 9}}, ErrFieldsOverlap},
+		{16, []Field{{Type{}, 0}}, ErrInvalidType},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewPacker(tt.width, tt.fields...); !errors.Is(err, tt.err) {
+			t.Fatalf("%d, %v: %v is not %v", tt.width, tt.fields, err, tt.err)
+		}
+	}
+
+	if _, err := NewPacker(32, Field{x4, 20}, Field{x4, 8}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkPackerSet(b *testing.B) {
+	x3, _ := NewTypeX3(5, false)
+	x4, _ := NewTypeX4(11, true)
+
+	p, err := NewPacker(16, Field{x3, 11}, Field{x4, 0})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		p.Set(i&1, float64(i%100)*0.01)
+	}
+	intResult = int(p.Word())
+}
@@ -0,0 +1,95 @@
+package toyfloat
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// parallelChunkSize is the number of values a goroutine handles
+// between cancellation checks.
+const parallelChunkSize = 1 << 16
+
+// EncodeSliceParallel encodes src into dst using several goroutines.
+// A non-positive number of workers means GOMAXPROCS.
+// The result is identical to calling Encode for each value.
+// It panics if the slices have different lengths.
+func EncodeSliceParallel(t *Type, dst []uint16, src []float64, workers int) {
+	_ = EncodeSliceParallelContext(context.Background(), t, dst, src, workers)
+}
+
+// DecodeSliceParallel decodes src into dst using several goroutines.
+// A non-positive number of workers means GOMAXPROCS.
+// The result is identical to calling Decode for each value.
+// It panics if the slices have different lengths.
+func DecodeSliceParallel(t *Type, dst []float64, src []uint16, workers int) {
+	_ = DecodeSliceParallelContext(context.Background(), t, dst, src, workers)
+}
+
+// EncodeSliceParallelContext is EncodeSliceParallel that stops
+// when the context is done. It returns the context error in that case,
+// and dst is left partially written.
+func EncodeSliceParallelContext(ctx context.Context, t *Type,
+	dst []uint16, src []float64, workers int) error {
+
+	checkLengths(len(dst), len(src))
+	return parallelize(ctx, len(src), workers, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			dst[i] = encode(src[i], t)
+		}
+	})
+}
+
+// DecodeSliceParallelContext is DecodeSliceParallel that stops
+// when the context is done. It returns the context error in that case,
+// and dst is left partially written.
+func DecodeSliceParallelContext(ctx context.Context, t *Type,
+	dst []float64, src []uint16, workers int) error {
+
+	checkLengths(len(dst), len(src))
+	return parallelize(ctx, len(src), workers, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			dst[i] = decode(src[i], t)
+		}
+	})
+}
+
+// parallelize splits [0, n) into chunks and hands them out to workers.
+// Each chunk is processed by exactly one of them.
+func parallelize(ctx context.Context, n, workers int, work func(lo, hi int)) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	chunks := (n + parallelChunkSize - 1) / parallelChunkSize
+	if workers > chunks {
+		workers = chunks
+	}
+
+	var next int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				chunk := int(atomic.AddInt64(&next, 1) - 1)
+				if chunk >= chunks {
+					return
+				}
+
+				lo := chunk * parallelChunkSize
+				hi := lo + parallelChunkSize
+				if hi > n {
+					hi = n
+				}
+				work(lo, hi)
+			}
+		}()
+	}
+
+	wg.Wait()
+	return ctx.Err()
+}
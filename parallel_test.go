@@ -0,0 +1,96 @@
+package toyfloat
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestParallelMatchesSerial(t *testing.T) {
+	tf := makeTypeX4(12, true, t)
+
+	r := rand.New(rand.NewSource(7))
+	values := make([]float64, 3*parallelChunkSize+123)
+	for i := range values {
+		values[i] = 600*r.Float64() - 300
+	}
+
+	for _, workers := range []int{0, 1, 3, 100} {
+		codes := make([]uint16, len(values))
+		EncodeSliceParallel(&tf, codes, values, workers)
+
+		decoded := make([]float64, len(values))
+		DecodeSliceParallel(&tf, decoded, codes, workers)
+
+		for i, v := range values {
+			code := tf.Encode(v)
+			if codes[i] != code {
+				t.Fatalf("#%d: 0x%X != 0x%X (%d workers)",
+					i, codes[i], code, workers)
+			}
+
+			if decoded[i] != tf.Decode(code) {
+				t.Fatalf("#%d: %f != %f (%d workers)",
+					i, decoded[i], tf.Decode(code), workers)
+			}
+		}
+	}
+
+	EncodeSliceParallel(&tf, nil, nil, 4)
+}
+
+func TestParallelCancellation(t *testing.T) {
+	tf := makeTypeX4(12, true, t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	values := make([]float64, 2*parallelChunkSize)
+	codes := make([]uint16, len(values))
+	err := EncodeSliceParallelContext(ctx, &tf, codes, values, 2)
+	if err != context.Canceled {
+		t.Fatalf("context.Canceled expected, got %v", err)
+	}
+
+	decoded := make([]float64, len(values))
+	err = DecodeSliceParallelContext(ctx, &tf, decoded, codes, 2)
+	if err != context.Canceled {
+		t.Fatalf("context.Canceled expected, got %v", err)
+	}
+
+	err = EncodeSliceParallelContext(context.Background(), &tf, codes, values, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParallelLengthMismatch(t *testing.T) {
+	tf := makeTypeX4(12, true, t)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("panic expected")
+		}
+	}()
+
+	EncodeSliceParallel(&tf, make([]uint16, 3), make([]float64, 4), 2)
+}
+
+func BenchmarkEncodeSliceParallel(b *testing.B) {
+	tf, e := NewTypeX4(12, true)
+	if e != nil {
+		b.Fatal(e)
+	}
+
+	const scale = 256.0 / 10000
+	values := make([]float64, 1<<20)
+	for i := range values {
+		values[i] = scale * float64(i%10000)
+	}
+
+	codes := make([]uint16, len(values))
+	for i := 0; i < b.N; i++ {
+		EncodeSliceParallel(&tf, codes, values, 0)
+	}
+	intResult = int(codes[len(codes)-1])
+}
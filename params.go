@@ -0,0 +1,208 @@
+package toyfloat
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Params are the arguments and options of NewType.
+type Params struct {
+	Length uint8
+	XBase  uint8
+	XSize  uint8
+	MinX   int
+	Signed bool
+
+	// NonFinite reserves the two largest magnitudes
+	// for infinity and NaN. See WithNonFinite.
+	NonFinite bool
+
+	// Reserved codes are never produced by Encode. See WithReserved.
+	Reserved []Sentinel
+
+	// NegativeZero is the mode for the code of -0. See WithNegativeZero.
+	NegativeZero NegativeZeroMode
+
+	// TwosComplement selects the layout of signed codes.
+	// See WithTwosComplement.
+	TwosComplement bool
+
+	// Layout is the order and the alignment of the fields.
+	// See WithLayout.
+	Layout Layout
+
+	// Tags is what happens to the extra bits. See WithTagPolicy.
+	Tags TagPolicy
+
+	// Protection fills the extra bits with check bits.
+	// See WithProtection.
+	Protection Protection
+}
+
+// paramsKey is Params as a comparable value.
+type paramsKey struct {
+	length, xBase, xSize uint8
+	minX                 int
+	signed, nonFinite    bool
+	reserved             string
+	negativeZero         NegativeZeroMode
+	twosComplement       bool
+	layout               Layout
+	tags                 TagPolicy
+	protection           Protection
+}
+
+func (p Params) key() paramsKey {
+	var reserved strings.Builder
+	for _, code := range p.Reserved {
+		reserved.WriteByte(byte(code >> 8))
+		reserved.WriteByte(byte(code))
+	}
+
+	return paramsKey{
+		length:         p.Length,
+		xBase:          p.XBase,
+		xSize:          p.XSize,
+		minX:           p.MinX,
+		signed:         p.Signed,
+		nonFinite:      p.NonFinite,
+		reserved:       reserved.String(),
+		negativeZero:   p.NegativeZero,
+		twosComplement: p.TwosComplement,
+		layout:         p.Layout,
+		tags:           p.Tags,
+		protection:     p.Protection,
+	}
+}
+
+// normalize sorts reserved codes, removes duplicates,
+// and keeps a single field order for unsigned types,
+// without changing the argument.
+func (p Params) normalize() Params {
+	p.Layout = normalizeLayout(p.Layout, p.Signed)
+
+	if len(p.Reserved) == 0 {
+		p.Reserved = nil
+		return p
+	}
+
+	codes := append([]Sentinel(nil), p.Reserved...)
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i] < codes[j]
+	})
+
+	unique := codes[:1]
+	for _, code := range codes[1:] {
+		if code != unique[len(unique)-1] {
+			unique = append(unique, code)
+		}
+	}
+
+	p.Reserved = unique
+	return p
+}
+
+// Option changes the way a type encodes values.
+type Option func(*Params)
+
+// WithNonFinite reserves codes for NaN and infinities.
+// The largest magnitude means infinity, with a sign for signed types,
+// and the one below it means NaN. So the ordering of the comparable
+// form places -Inf lowest and +Inf highest, and MaxValue decreases.
+//
+// Finite values out of range are still saturated,
+// only infinite ones are encoded as infinity.
+// Unsigned types encode -Inf as zero, like any negative number.
+func WithNonFinite() Option {
+	return func(p *Params) {
+		p.NonFinite = true
+	}
+}
+
+// NewTypeFromParams is NewType with arguments taken from a struct.
+func NewTypeFromParams(p Params) (Type, error) {
+	p = p.normalize()
+
+	var err error
+	if (p.XBase < 2) || (p.XBase > 10) {
+		err = ErrBaseOutOfRange
+	} else if p.MinX >= 0 {
+		err = ErrMinExponentNotNegative
+	} else if math.IsInf(math.Pow(float64(p.XBase), -float64(p.MinX)), +1) {
+		// So all the scales are finite and not zero.
+		err = ErrMinExponentTooSmall
+	}
+
+	t := Type{}
+	if err == nil {
+		t, err = newSettings(p)
+	}
+
+	if err != nil {
+		return Type{}, &ParamError{Params: p, Err: err}
+	}
+	return t, nil
+}
+
+// Params returns the arguments and options the type was made from.
+func (t *Type) Params() Params {
+	return Params{
+		Length:         t.length,
+		XBase:          t.xBase,
+		XSize:          t.xSize,
+		MinX:           t.minX,
+		Signed:         0 != t.minus,
+		NonFinite:      t.nonFinite,
+		Reserved:       t.Reserved(),
+		NegativeZero:   t.zeroMode,
+		TwosComplement: t.twos,
+		Layout:         t.layout,
+		Tags:           t.tags,
+		Protection:     t.protection,
+	}
+}
+
+// String returns the spec of the parameters. See Type.Spec.
+func (p Params) String() string {
+	sign := 'u'
+	if p.Signed {
+		sign = 's'
+	}
+
+	s := fmt.Sprintf("%c%dx%db%dm%d", sign, p.Length, p.XSize, p.XBase, p.MinX)
+	if p.NonFinite {
+		s += "+nonfinite"
+	}
+
+	if KeepNegativeZero != p.NegativeZero {
+		s += "+zero=" + p.NegativeZero.String()
+	}
+
+	if p.TwosComplement {
+		s += "+twos"
+	}
+
+	if (Layout{}) != p.Layout {
+		s += "+layout=" + p.Layout.String()
+	}
+
+	if TagsIgnored != p.Tags {
+		s += "+tags=" + p.Tags.String()
+	}
+
+	if NoProtection != p.Protection {
+		s += "+protect=" + p.Protection.String()
+	}
+
+	for i, code := range p.Reserved {
+		if i == 0 {
+			s += "+reserved="
+		} else {
+			s += "."
+		}
+		s += fmt.Sprintf("%x", uint16(code))
+	}
+	return s
+}
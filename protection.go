@@ -0,0 +1,210 @@
+package toyfloat
+
+import "math/bits"
+
+// Protection is a code that fills the extra bits with check bits.
+// See WithProtection.
+type Protection uint8
+
+const (
+	// NoProtection is the default.
+	NoProtection Protection = iota
+
+	// Parity is a single even parity bit. It detects single-bit errors.
+	Parity
+
+	// Hamming is a Hamming code with as few check bits as possible.
+	// It corrects single-bit errors, so it needs more extra bits:
+	// 3 for up to 4 bits long types, 4 for up to 11 bits.
+	Hamming
+
+	// CRC4 is CRC-4 with the polynomial x^4+x+1.
+	// It detects single-bit errors, and double-bit errors
+	// less than 15 bits apart. It corrects single-bit errors
+	// in types up to 11 bits long.
+	CRC4
+)
+
+// String returns the name of the protection, as in a spec.
+func (p Protection) String() string {
+	switch p {
+	case NoProtection:
+		return "none"
+	case Parity:
+		return "parity"
+	case Hamming:
+		return "hamming"
+	case CRC4:
+		return "crc4"
+	}
+	return "invalid"
+}
+
+// WithProtection stores check bits in the extra bits,
+// which are then not available for tags.
+// Encode, Abs, FromComparable and UseIntegerDelta return protected codes.
+// Decode ignores the check bits, so call Verify before it.
+//
+// Types made WithTwosComplement need the layout aligned to the most-significant
+// bit, since their extra bits are the sign extension otherwise.
+func WithProtection(protection Protection) Option {
+	return func(p *Params) {
+		p.Protection = protection
+	}
+}
+
+// Protect replaces the extra bits of the code with its check bits.
+// Codes that are not protected yet, such as the ones made by hand,
+// must be protected before sending.
+func (t *Type) Protect(code uint16) uint16 {
+	return t.WithTag(code, checkBits(t.index(code), t))
+}
+
+// Verify checks the code and corrects a single-bit error,
+// if the protection allows it.
+// It returns the code with valid check bits,
+// or ErrCorruptedCode, if the error cannot be corrected.
+// Codes of types without protection are always valid.
+func (t *Type) Verify(code uint16) (uint16, error) {
+	if NoProtection == t.protection {
+		return code, nil
+	}
+
+	// Unused extra bits are not protected.
+	value := t.index(code)
+	check := t.Tag(code) & checkMask(t)
+
+	syndrome := checkBits(value, t) ^ check
+	if 0 == syndrome {
+		return t.Protect(code), nil
+	} else if !t.correctable {
+		return code, ErrCorruptedCode
+	}
+
+	// Syndromes of single-bit errors are all different,
+	// since the type is correctable.
+	for i := uint8(0); i < t.length; i++ {
+		if checkBits(uint16(1)<<i, t) == syndrome {
+			return t.Protect(code ^ (uint16(1) << i << t.align)), nil
+		}
+	}
+
+	// An error in one of the check bits.
+	if 1 == bits.OnesCount16(syndrome) {
+		return t.Protect(code), nil
+	}
+	return code, ErrCorruptedCode
+}
+
+// ----------------
+
+// checkBitCount returns the number of check bits of the protection
+// for a type of the length, or 0, if it cannot protect it.
+func checkBitCount(protection Protection, length uint8) uint8 {
+	switch protection {
+	case Parity:
+		return 1
+	case Hamming:
+		// 2^r - r - 1 data bits.
+		for r := uint8(2); r <= 5; r++ {
+			if (int(1)<<r)-int(r)-1 >= int(length) {
+				return r
+			}
+		}
+	case CRC4:
+		return 4
+	}
+	return 0
+}
+
+func setProtection(s *Type, protection Protection) error {
+	if NoProtection == protection {
+		return nil
+	}
+
+	size := checkBitCount(protection, s.length)
+	if (0 == size) || (size > 16-s.length) {
+		return ErrInvalidProtection
+	} else if TagsIgnored != s.tags {
+		// Check bits are not a tag.
+		return ErrInvalidProtection
+	} else if s.twos && (0 == s.align) {
+		// The extra bits are the sign extension.
+		return ErrInvalidProtection
+	}
+
+	s.protection = protection
+	s.checkSize = size
+
+	// Single-bit errors are correctable, if their syndromes
+	// are all different. Check bits have the syndromes 1, 2, 4...
+	seen := make(map[uint16]bool)
+	for i := uint8(0); i < size; i++ {
+		seen[uint16(1)<<i] = true
+	}
+
+	s.correctable = true
+	for i := uint8(0); i < s.length; i++ {
+		syndrome := checkBits(uint16(1)<<i, s)
+		if (0 == syndrome) || seen[syndrome] {
+			s.correctable = false
+			break
+		}
+		seen[syndrome] = true
+	}
+	return nil
+}
+
+func checkMask(t *Type) uint16 {
+	return (uint16(1) << t.checkSize) - 1
+}
+
+// checkBits expects the value bits of a code without extra bits.
+func checkBits(value uint16, t *Type) uint16 {
+	switch t.protection {
+	case Parity:
+		return uint16(bits.OnesCount16(value) & 1)
+	case Hamming:
+		return hammingBits(value)
+	case CRC4:
+		return crc4(value, t.length)
+	}
+	return 0
+}
+
+// hammingBits is the XOR of the columns of the set bits.
+// The columns are the numbers that are not powers of two: 3, 5, 6, 7, 9...
+func hammingBits(value uint16) uint16 {
+	r := uint16(0)
+	column := uint16(2)
+	for ; 0 != value; value >>= 1 {
+		column++
+		for 0 == column&(column-1) {
+			column++
+		}
+
+		if 0 != value&1 {
+			r ^= column
+		}
+	}
+	return r
+}
+
+// crc4 processes the bits from the least-significant one,
+// and reverses the result. So the degrees of the codeword polynomial
+// go down from bit 0 of the value to the last check bit,
+// and bits that are less than 15 apart have different degrees modulo 15.
+func crc4(value uint16, length uint8) uint16 {
+	const polynomial = 0x3 // x^4+x+1 without x^4
+
+	r := uint16(0)
+	for i := uint8(0); i < length; i++ {
+		bit := (value >> i) & 1
+		top := (r >> 3) & 1
+		r = (r << 1) & 0xF
+		if 0 != bit^top {
+			r ^= polynomial
+		}
+	}
+	return bits.Reverse16(r) >> 12
+}
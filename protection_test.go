@@ -0,0 +1,200 @@
+package toyfloat
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProtectionDetectsSingleBitErrors(t *testing.T) {
+	for _, protection := range []Protection{Parity, Hamming, CRC4} {
+		for _, length := range []int{6, 11, 12, 15} {
+			tf, err := NewTypeX4(length, true, WithProtection(protection))
+			if errors.Is(err, ErrInvalidProtection) {
+				// Too few extra bits.
+				continue
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			total := uint8(length) + tf.checkSize
+			for f := -255.0; f <= 255.0; f += 0.5 {
+				code := tf.Encode(f)
+				if verified, err := tf.Verify(code); (err != nil) || (verified != code) {
+					t.Fatalf("%s: 0x%X -> 0x%X, %v", tf.Spec(), code, verified, err)
+				}
+
+				for i := uint8(0); i < total; i++ {
+					corrupted := code ^ (uint16(1) << i)
+					verified, err := tf.Verify(corrupted)
+
+					if tf.correctable {
+						if (err != nil) || (verified != code) {
+							t.Fatalf("%s: 0x%X -> 0x%X, %v", tf.Spec(), corrupted, verified, err)
+						}
+					} else if !errors.Is(err, ErrCorruptedCode) {
+						t.Fatalf("%s: 0x%X: ErrCorruptedCode expected", tf.Spec(), corrupted)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestProtectionCorrection(t *testing.T) {
+	tests := []struct {
+		length      int
+		protection  Protection
+		correctable bool
+	}{
+		{12, Parity, false},
+		{12, CRC4, false},
+		{11, CRC4, true},
+		{11, Hamming, true},
+		{4, Hamming, true},
+	}
+
+	for _, tt := range tests {
+		tf, err := NewTypeX2(tt.length, false, WithProtection(tt.protection))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tf.correctable != tt.correctable {
+			t.Fatalf("%s: correctable must be %t", tf.Spec(), tt.correctable)
+		}
+	}
+
+	// A sign or exponent error must not turn 0.5 into -200.
+	tf := makeTypeX4(12, true, t, WithProtection(CRC4))
+	code := tf.Encode(0.5)
+	for _, bit := range []uint16{0x800, 0x400, 0x100} {
+		if _, err := tf.Verify(code ^ bit); !errors.Is(err, ErrCorruptedCode) {
+			t.Fatalf("0x%X: ErrCorruptedCode expected", code^bit)
+		}
+	}
+
+	// Double errors are detected by CRC-4, unless they are
+	// 15 bits apart, which is the period of the polynomial.
+	for i := uint(0); i < 16; i++ {
+		for j := i + 1; j < i+15 && j < 16; j++ {
+			corrupted := code ^ (1 << i) ^ (1 << j)
+			if _, err := tf.Verify(corrupted); !errors.Is(err, ErrCorruptedCode) {
+				t.Fatalf("0x%X: ErrCorruptedCode expected", corrupted)
+			}
+		}
+	}
+}
+
+func TestProtectionResults(t *testing.T) {
+	tf := makeTypeX4(11, true, t, WithProtection(Hamming))
+	plain := makeTypeX4(11, true, t)
+
+	last := tf.Encode(-200)
+	for f := -200.0; f <= 200.0; f += 0.1 {
+		code := tf.Encode(f)
+		if tf.StripTag(code) != plain.Encode(f) {
+			t.Fatalf("%f: 0x%X", f, code)
+		} else if tf.Decode(code) != plain.Decode(plain.Encode(f)) {
+			t.Fatalf("%f: check bits change the value", f)
+		}
+
+		for _, result := range []uint16{
+			tf.Abs(code),
+			tf.FromComparable(tf.ToComparable(code)),
+			tf.UseIntegerDelta(last, tf.GetIntegerDelta(last, code)),
+		} {
+			if verified, err := tf.Verify(result); (err != nil) || (verified != result) {
+				t.Fatalf("%f: 0x%X is not protected", f, result)
+			}
+		}
+		last = code
+	}
+
+	// Codes made by hand.
+	if code := tf.Protect(0x123); tf.StripTag(code) != 0x123 {
+		t.Fatalf("0x%X", code)
+	} else if _, err := tf.Verify(code); err != nil {
+		t.Fatal(err)
+	}
+
+	msb, err := NewTypeX4(12, true, WithProtection(Parity),
+		WithLayout(Layout{AlignMSB: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := msb.Encode(-3)
+	if _, err := msb.Verify(code ^ 0x100); !errors.Is(err, ErrCorruptedCode) {
+		t.Fatalf("0x%X: ErrCorruptedCode expected", code^0x100)
+	}
+
+	// Types without protection.
+	if verified, err := plain.Verify(0xFFFF); (err != nil) || (verified != 0xFFFF) {
+		t.Fatalf("0x%X, %v", verified, err)
+	}
+}
+
+func TestProtectionParams(t *testing.T) {
+	for _, protection := range []Protection{Parity, Hamming, CRC4} {
+		tf := makeTypeX4(10, true, t, WithProtection(protection))
+		if tf.Params().Protection != protection {
+			t.Fatalf("%s != %s", tf.Params().Protection, protection)
+		}
+
+		parsed, err := ParseType(tf.Spec())
+		if err != nil {
+			t.Fatal(err)
+		} else if parsed != tf {
+			t.Fatalf("%s: different types", tf.Spec())
+		}
+	}
+
+	tf := makeTypeX4(12, true, t, WithProtection(CRC4))
+	if spec := tf.Spec(); spec != "s12x4b2m-8+protect=crc4" {
+		t.Fatalf("%s != s12x4b2m-8+protect=crc4", spec)
+	}
+
+	for _, options := range [][]Option{
+		{WithProtection(Hamming)},
+		{WithProtection(CRC4 + 1)},
+		{WithProtection(Parity), WithTagPolicy(TagsPreserved)},
+		{WithProtection(Parity), WithTwosComplement()},
+	} {
+		_, err := NewTypeX4(12, true, options...)
+		if !errors.Is(err, ErrInvalidProtection) {
+			t.Fatalf("ErrInvalidProtection expected, got %v", err)
+		}
+	}
+
+	_, err := NewTypeX4(16, true, WithProtection(Parity))
+	if !errors.Is(err, ErrInvalidProtection) {
+		t.Fatalf("ErrInvalidProtection expected, got %v", err)
+	}
+
+	aligned := makeTypeX4(12, true, t, WithProtection(Parity), WithTwosComplement(),
+		WithLayout(Layout{Order: OrderSXM, AlignMSB: true}))
+	for f := -255.0; f < 255; f += 0.1 {
+		a, b := aligned.Encode(f), aligned.Encode(f+0.1)
+		if int16(a) > int16(b) {
+			t.Fatalf("%f: 0x%X > 0x%X", f, a, b)
+		}
+	}
+
+	if _, err := ParseType("s12x4b2m-8+protect=none"); err == nil {
+		t.Fatalf("error expected")
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	tf, err := NewTypeX4(12, true, WithProtection(CRC4))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	code := tf.Encode(0.5)
+	r := uint16(0)
+	for i := 0; i < b.N; i++ {
+		r, _ = tf.Verify(code)
+	}
+	intResult = int(r)
+}
@@ -0,0 +1,139 @@
+package toyfloat
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Registry caches types by parameters and gives names to them.
+// It is safe for concurrent use.
+type Registry struct {
+	mutex sync.RWMutex
+	names map[string]Params
+	types map[paramsKey]Type
+}
+
+// NewRegistry makes a registry with the presets.
+//
+// Preset names follow the ones used in the changelog and the readme:
+// the length, then "x2" or "x3" for non-default exponents
+// (see NewTypeX2, NewTypeX3), then "u" for unsigned types.
+// For example, "12", "12u", "15x3", "16x2", "3x2u".
+func NewRegistry() *Registry {
+	r := &Registry{
+		names: make(map[string]Params),
+		types: make(map[paramsKey]Type),
+	}
+
+	presets := []struct {
+		suffix string
+		xBase  uint8
+		xSize  uint8
+		minX   int
+	}{
+		{"x2", 3, 2, -3},
+		{"x3", 2, 3, -6},
+		{"", 2, 4, -8},
+	}
+
+	for _, preset := range presets {
+		for length := uint8(3); length <= 16; length++ {
+			for _, signed := range []bool{true, false} {
+				p := Params{
+					Length: length,
+					XBase:  preset.xBase,
+					XSize:  preset.xSize,
+					MinX:   preset.minX,
+					Signed: signed,
+				}
+
+				// Too short lengths.
+				if _, err := r.FromParams(p); err != nil {
+					continue
+				}
+
+				name := fmt.Sprintf("%d%s", length, preset.suffix)
+				if !signed {
+					name += "u"
+				}
+				r.names[name] = p
+			}
+		}
+	}
+
+	return r
+}
+
+// FromParams returns a cached type, making it on the first call.
+func (r *Registry) FromParams(p Params) (Type, error) {
+	key := p.key()
+
+	r.mutex.RLock()
+	t, ok := r.types[key]
+	r.mutex.RUnlock()
+
+	if ok {
+		return t, nil
+	}
+
+	t, err := NewTypeFromParams(p)
+	if err != nil {
+		return Type{}, err
+	}
+
+	r.mutex.Lock()
+	r.types[key] = t
+	r.mutex.Unlock()
+
+	return t, nil
+}
+
+// Lookup returns the type registered with the name.
+func (r *Registry) Lookup(name string) (Type, bool) {
+	r.mutex.RLock()
+	p, ok := r.names[name]
+	r.mutex.RUnlock()
+
+	if !ok {
+		return Type{}, false
+	}
+
+	// Registered parameters are always valid.
+	t, err := r.FromParams(p)
+	return t, err == nil
+}
+
+// Register gives a name to the type with the parameters.
+// Registering the same name twice is allowed only with the same parameters.
+func (r *Registry) Register(name string, p Params) error {
+	if _, err := r.FromParams(p); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if existing, ok := r.names[name]; ok && (existing.key() != p.normalize().key()) {
+		return fmt.Errorf("name %s: %w", name, ErrNameRegistered)
+	}
+
+	r.names[name] = p.normalize()
+	return nil
+}
+
+var defaultRegistry = NewRegistry()
+
+// FromParams returns a type from the default registry.
+func FromParams(p Params) (Type, error) {
+	return defaultRegistry.FromParams(p)
+}
+
+// Lookup finds a type by name in the default registry.
+func Lookup(name string) (Type, bool) {
+	return defaultRegistry.Lookup(name)
+}
+
+// Register adds a name to the default registry.
+func Register(name string, p Params) error {
+	return defaultRegistry.Register(name, p)
+}
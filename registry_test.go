@@ -0,0 +1,136 @@
+package toyfloat
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestPresets(t *testing.T) {
+	presets := map[string]Type{
+		"12":   makeTypeX4(12, true, t),
+		"12u":  makeTypeX4(12, false, t),
+		"13":   makeTypeX4(13, true, t),
+		"14":   makeTypeX4(14, true, t),
+		"15x3": makeTypeX3(15, true, t),
+		"16x2": makeTypeX2(16, true, t),
+		"3x2u": makeTypeX2(3, false, t),
+		"4x3u": makeTypeX3(4, false, t),
+		"8x3":  makeTypeX3(8, true, t),
+	}
+
+	for name, expected := range presets {
+		tf, ok := Lookup(name)
+		if !ok {
+			t.Fatalf("%s is not found", name)
+		}
+
+		if tf != expected {
+			t.Fatalf("%s is a different type", name)
+		}
+	}
+
+	for _, name := range []string{"3x2", "5", "m11x3", "", "12x4"} {
+		if _, ok := Lookup(name); ok {
+			t.Fatalf("%s must not exist", name)
+		}
+	}
+}
+
+func TestParamsRoundTrip(t *testing.T) {
+	tf := makeTypeX3(15, true, t)
+
+	p := tf.Params()
+	expected := Params{Length: 15, XBase: 2, XSize: 3, MinX: -6, Signed: true}
+	if p.String() != expected.String() {
+		t.Fatalf("%+v != %+v", p, expected)
+	}
+
+	same, err := NewTypeFromParams(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if same != tf {
+		t.Fatalf("different types")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	r := NewRegistry()
+	p := Params{Length: 8, XBase: 10, XSize: 3, MinX: -2, Signed: true}
+
+	if err := r.Register("d8x3", p); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register("d8x3", p); err != nil {
+		t.Fatalf("the same parameters must be allowed: %v", err)
+	}
+
+	other := p
+	other.MinX = -3
+	if err := r.Register("d8x3", other); !errors.Is(err, ErrNameRegistered) {
+		t.Fatalf("ErrNameRegistered expected: the name is taken, got %v", err)
+	}
+
+	if err := r.Register("12", other); !errors.Is(err, ErrNameRegistered) {
+		t.Fatalf("ErrNameRegistered expected: presets must not be replaced, got %v", err)
+	}
+
+	invalid := Params{Length: 8, XBase: 1, XSize: 3, MinX: -2}
+	if err := r.Register("bad", invalid); err == nil {
+		t.Fatalf("error expected: invalid parameters")
+	}
+
+	tf, ok := r.Lookup("d8x3")
+	if !ok {
+		t.Fatalf("d8x3 is not found")
+	}
+
+	if tf.Params().String() != p.String() {
+		t.Fatalf("%+v != %+v", tf.Params(), p)
+	}
+
+	if _, ok := Lookup("d8x3"); ok {
+		t.Fatalf("the default registry must not be affected")
+	}
+}
+
+func TestRegistryConcurrency(t *testing.T) {
+	r := NewRegistry()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p := Params{Length: uint8(8 + i), XBase: 2, XSize: 3, MinX: -i - 1}
+			custom := Params{Length: 8, XBase: 2, XSize: 3, MinX: -1, Signed: true}
+			for j := 0; j < 100; j++ {
+				if err := r.Register("custom", custom); err != nil {
+					t.Error(err)
+				}
+
+				if _, err := r.FromParams(p); err != nil {
+					t.Error(err)
+				}
+
+				if _, ok := r.Lookup("15x3"); !ok {
+					t.Error("15x3 is not found")
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkFromParams(b *testing.B) {
+	p := Params{Length: 12, XBase: 2, XSize: 4, MinX: -8, Signed: true}
+	for i := 0; i < b.N; i++ {
+		_, e := FromParams(p)
+		if e != nil {
+			b.Fatal(e)
+		}
+	}
+}
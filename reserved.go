@@ -0,0 +1,182 @@
+package toyfloat
+
+import "math"
+
+// Sentinel is a reserved code, such as "sensor offline"
+// or "not applicable". Its meaning is up to the user.
+type Sentinel uint16
+
+// WithReserved reserves codes, so that Encode never produces them.
+// A number that would be encoded as a reserved code is encoded
+// as the nearest code that is not reserved.
+// Decode returns NaN for reserved codes, and DecodeSentinel reports them.
+//
+// The integer delta functions skip reserved codes, so the deltas
+// are counted in codes that Encode can produce.
+// A reserved code passed to them is treated as the next one
+// in the comparable order that is not reserved.
+//
+// Codes are given without extra most-significant bits,
+// in the layout of the type.
+func WithReserved(codes ...Sentinel) Option {
+	return func(p *Params) {
+		p.Reserved = append(p.Reserved, codes...)
+	}
+}
+
+// IsReserved reports whether the code is reserved.
+// Extra most-significant bits are ignored.
+func (t *Type) IsReserved(x uint16) bool {
+	return t.IsValid() && (nil != t.data.reserved) &&
+		t.data.reserved.is[fromLayout(x, t)&t.bitmask]
+}
+
+// Reserved returns the reserved codes in ascending order.
+func (t *Type) Reserved() []Sentinel {
+	if !t.IsValid() || (nil == t.data.reserved) {
+		return nil
+	}
+	return append([]Sentinel(nil), t.data.reserved.codes...)
+}
+
+// DecodeSentinel is Decode that reports reserved codes.
+// For them, it returns NaN, the code without extra bits and true.
+func (t *Type) DecodeSentinel(x uint16) (float64, Sentinel, bool) {
+	if t.IsReserved(x) {
+		return math.NaN(), Sentinel(x & (t.bitmask << t.align)), true
+	}
+	return decode(x, t), 0, false
+}
+
+// ----------------
+
+// reservedTable is built once per type.
+// Ranks count the codes that are not reserved in the comparable order.
+type reservedTable struct {
+	codes []Sentinel
+
+	// indexed by a code
+	is []bool
+
+	// comparable form -> number of free comparable codes below it
+	rank []uint16
+
+	// rank -> comparable form
+	free []uint16
+}
+
+func checkReserved(codes []Sentinel, s *Type) error {
+	if len(codes) == 0 {
+		return nil
+	}
+
+	for _, code := range codes {
+		if 0 != uint16(code)&^(s.bitmask<<s.align) {
+			return ErrInvalidReservedCode
+		} else if s.nonFinite && (fromLayout(uint16(code), s)&s.infCode >= s.nanCode) {
+			return ErrInvalidReservedCode
+		}
+	}
+
+	// The code of -0 is not a number under the other modes.
+	left := int(s.bitmask) + 1 - len(codes)
+	if (KeepNegativeZero != s.zeroMode) && !containsCode(codes, s.minus, s) {
+		left--
+	}
+
+	if left < 1 {
+		return ErrTooFewCodes
+	}
+	return nil
+}
+
+func containsCode(codes []Sentinel, x uint16, s *Type) bool {
+	for _, code := range codes {
+		if fromLayout(uint16(code), s)&s.bitmask == x {
+			return true
+		}
+	}
+	return false
+}
+
+// newReservedTable expects checked and sorted codes.
+func newReservedTable(codes []Sentinel, s *Type) *reservedTable {
+	if len(codes) == 0 {
+		return nil
+	}
+
+	size := int(s.bitmask) + 1
+	r := &reservedTable{
+		codes: codes,
+		is:    make([]bool, size),
+		rank:  make([]uint16, size),
+		free:  make([]uint16, 0, size-len(codes)),
+	}
+
+	for _, code := range codes {
+		r.is[fromLayout(uint16(code), s)] = true
+	}
+
+	for c := 0; c < size; c++ {
+		r.rank[c] = uint16(len(r.free))
+		code := fromComparable(uint16(c), s) & s.bitmask
+
+		// Comparable zero of CanonicalZero types is not used,
+		// and the marker of NegativeZeroMarker types is not a number.
+		if toComparable(code, s) != uint16(c) {
+			continue
+		} else if (NegativeZeroMarker == s.zeroMode) && (0 == c) {
+			continue
+		}
+
+		if !r.is[code] {
+			r.free = append(r.free, uint16(c))
+		}
+	}
+	return r
+}
+
+func avoidReserved(code uint16, value float64, s *Type) uint16 {
+	r := s.data.reserved
+	if !r.is[code] {
+		return code
+	}
+
+	// The free codes around the reserved one.
+	rank := int(r.rank[toComparable(code, s)])
+	if rank == 0 {
+		return fromComparable(r.free[0], s) & s.bitmask
+	} else if rank == len(r.free) {
+		return fromComparable(r.free[rank-1], s) & s.bitmask
+	}
+
+	below := fromComparable(r.free[rank-1], s) & s.bitmask
+	above := fromComparable(r.free[rank], s) & s.bitmask
+
+	if math.Abs(decodeNumber(below, s)-value) < math.Abs(decodeNumber(above, s)-value) {
+		return below
+	}
+	return above
+}
+
+func encodeReservedDelta(last, x uint16, s *Type) int {
+	r := s.data.reserved
+	a := int(r.rank[codeToComparable(last, s)])
+	b := int(r.rank[codeToComparable(x, s)])
+	return b - a
+}
+
+func decodeReservedDelta(last uint16, delta int, s *Type) uint16 {
+	r := s.data.reserved
+	lastRank := int(r.rank[codeToComparable(last, s)])
+
+	// The same saturation as without reserved codes.
+	rank := 0
+	if delta > len(r.free)-1-lastRank {
+		rank = len(r.free) - 1
+	} else if delta >= -lastRank {
+		rank = lastRank + delta
+	}
+
+	return comparableToCode(r.free[rank], s)
+}
@@ -0,0 +1,238 @@
+package toyfloat
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+const (
+	sensorOffline Sentinel = 0x7FF
+	notApplicable Sentinel = 0x7FE
+	clipped       Sentinel = 0x448 // 1.564706
+)
+
+func TestReservedEncoding(t *testing.T) {
+	tf := makeTypeX4(12, true, t, WithReserved(sensorOffline, notApplicable, clipped))
+	plain := makeTypeX4(12, true, t)
+
+	for f := -300.0; f <= 300.0; f += 0.001 {
+		code := tf.Encode(f)
+		if tf.IsReserved(code) {
+			t.Fatalf("%f -> reserved 0x%X", f, code)
+		}
+
+		expected := plain.Encode(f)
+		if !tf.IsReserved(expected) && (code != expected) {
+			t.Fatalf("%f -> 0x%X, expected 0x%X", f, code, expected)
+		}
+	}
+
+	// The nearest code that is not reserved.
+	below := tf.Encode(1.5647)
+	if below != 0x447 {
+		t.Fatalf("0x%X != 0x447", below)
+	}
+
+	above := tf.Encode(1.5648)
+	if above != 0x449 {
+		t.Fatalf("0x%X != 0x449", above)
+	}
+
+	// The maximum is reserved, so the values are saturated below it.
+	if code := tf.Encode(1000); code != 0x7FD {
+		t.Fatalf("0x%X != 0x7FD", code)
+	}
+}
+
+func TestReservedDecoding(t *testing.T) {
+	tf := makeTypeX4(12, true, t, WithReserved(sensorOffline, notApplicable, clipped))
+
+	for _, s := range []Sentinel{sensorOffline, notApplicable, clipped} {
+		if !math.IsNaN(tf.Decode(uint16(s))) {
+			t.Fatalf("0x%X must be NaN", s)
+		}
+
+		v, sentinel, ok := tf.DecodeSentinel(0xF000 | uint16(s))
+		if !ok || (sentinel != s) || !math.IsNaN(v) {
+			t.Fatalf("0x%X: %f, 0x%X, %t", s, v, sentinel, ok)
+		}
+	}
+
+	v, sentinel, ok := tf.DecodeSentinel(tf.Encode(1))
+	if ok || (sentinel != 0) || (v != 1) {
+		t.Fatalf("1: %f, 0x%X, %t", v, sentinel, ok)
+	}
+
+	// Negative codes are not reserved.
+	if tf.IsReserved(tf.minus | uint16(clipped)) {
+		t.Fatalf("-0x%X is not reserved", clipped)
+	}
+}
+
+func TestReservedDelta(t *testing.T) {
+	tf := makeTypeX4(12, true, t, WithReserved(sensorOffline, notApplicable, clipped))
+
+	// 0x447 and 0x449 are neighbours now.
+	if d := tf.GetIntegerDelta(0x447, 0x449); d != 1 {
+		t.Fatalf("%d != 1", d)
+	}
+
+	if next := tf.UseIntegerDelta(0x447, 1); next != 0x449 {
+		t.Fatalf("0x%X != 0x449", next)
+	}
+
+	if max := tf.UseIntegerDelta(0x7FD, 10); max != 0x7FD {
+		t.Fatalf("0x%X != 0x7FD", max)
+	}
+
+	last := tf.Encode(-256)
+	for x := -256.0; x <= 256.0; x += 0.01 {
+		code := tf.Encode(x)
+		delta := tf.GetIntegerDelta(last, code)
+		result := tf.UseIntegerDelta(last, delta)
+
+		if result&tf.bitmask != code {
+			t.Fatalf("0x%X + %d = 0x%X, expected 0x%X",
+				last, delta, result, code)
+		}
+
+		if tf.IsReserved(result) {
+			t.Fatalf("0x%X is reserved", result)
+		}
+
+		last = code
+	}
+}
+
+func TestReservedMarkerDelta(t *testing.T) {
+	tf, err := NewTypeX2(5, true, WithNegativeZero(NegativeZeroMarker), WithReserved(0x1, 0x11))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lowest := tf.Encode(math.Inf(-1))
+	if d := tf.GetIntegerDelta(lowest, tf.Encode(0)); d != 14 {
+		t.Fatalf("%d != 14", d)
+	}
+
+	low := tf.UseIntegerDelta(tf.Encode(1), -100)
+	if low&tf.bitmask != lowest || tf.IsNegativeZero(low) {
+		t.Fatalf("0x%X != 0x%X", low, lowest)
+	}
+
+	for i := 0; i < 1<<5; i++ {
+		last := uint16(i)
+		if tf.IsReserved(last) || tf.IsNegativeZero(last) {
+			continue
+		}
+
+		for j := 0; j < 1<<5; j++ {
+			code := uint16(j)
+			if tf.IsReserved(code) || tf.IsNegativeZero(code) {
+				continue
+			}
+
+			next := tf.UseIntegerDelta(last, tf.GetIntegerDelta(last, code))
+			if next&tf.bitmask != code {
+				t.Fatalf("0x%X -> 0x%X: 0x%X", last, code, next)
+			}
+		}
+	}
+
+	// The marker is not left for numbers.
+	_, err = NewTypeX2(4, true, WithNegativeZero(NegativeZeroMarker),
+		WithReserved(0, 1, 2, 3, 4, 5, 6, 7, 9, 0xA, 0xB, 0xC, 0xD, 0xE, 0xF))
+	if !errors.Is(err, ErrTooFewCodes) {
+		t.Fatalf("ErrTooFewCodes expected, got %v", err)
+	}
+}
+
+func TestReservedAbs(t *testing.T) {
+	tf := makeTypeX4(12, true, t, WithReserved(0x005))
+
+	abs := tf.Abs(0x805)
+	if tf.IsReserved(abs) || abs != tf.Encode(-tf.Decode(0x805)) {
+		t.Fatalf("0x%X", abs)
+	} else if abs := tf.Abs(0x806); abs != 0x006 {
+		t.Fatalf("0x%X != 0x006", abs)
+	} else if abs := tf.Abs(0x005); abs != 0x005 {
+		t.Fatalf("0x%X != 0x005", abs)
+	}
+}
+
+func TestReservedParams(t *testing.T) {
+	tf := makeTypeX4(12, true, t, WithReserved(sensorOffline, notApplicable, clipped))
+
+	same, err := NewTypeX4(12, true,
+		WithReserved(clipped, sensorOffline), WithReserved(notApplicable, clipped))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if same != tf {
+		t.Fatalf("the order of reserved codes must not matter")
+	}
+
+	plain := makeTypeX4(12, true, t)
+	if tf == plain {
+		t.Fatalf("the option must make a different type")
+	}
+
+	if IsCompatible(&tf, &plain) || IsCompatible(&tf, &tf) {
+		t.Fatalf("reserved codes cannot be shifted")
+	}
+
+	const spec = "s12x4b2m-8+reserved=448.7fe.7ff"
+	if tf.Spec() != spec {
+		t.Fatalf("%s != %s", tf.Spec(), spec)
+	}
+
+	parsed, err := ParseType(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed != tf {
+		t.Fatalf("different types")
+	}
+
+	for _, bad := range []string{
+		"s12x4b2m-8+reserved=",
+		"s12x4b2m-8+reserved=7ff.7fe",
+		"s12x4b2m-8+reserved=7FF",
+		"s12x4b2m-8+reserved=07ff",
+		"s12x4b2m-8+reserved=7ff..",
+		"s12x4b2m-8+reserved=10000"} {
+
+		if _, err := ParseType(bad); err == nil {
+			t.Fatalf("%s: error expected", bad)
+		}
+	}
+
+	_, err = NewTypeX4(12, true, WithReserved(0x1000))
+	if !errors.Is(err, ErrInvalidReservedCode) {
+		t.Fatalf("ErrInvalidReservedCode expected, got %v", err)
+	}
+
+	_, err = NewTypeX4(12, true, WithNonFinite(), WithReserved(0x7FE))
+	if !errors.Is(err, ErrInvalidReservedCode) {
+		t.Fatalf("ErrInvalidReservedCode expected, got %v", err)
+	}
+
+	_, err = NewTypeX2(3, false, WithReserved(0, 1, 2, 3, 4, 5, 6, 7))
+	if !errors.Is(err, ErrTooFewCodes) {
+		t.Fatalf("ErrTooFewCodes expected, got %v", err)
+	}
+
+	tiny, err := NewTypeX2(3, false, WithReserved(0, 1, 2, 3, 4, 6, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []float64{-1, 0, 1, 2, 3, math.NaN()} {
+		if code := tiny.Encode(v); code != 5 {
+			t.Fatalf("%f -> 0x%X", v, code)
+		}
+	}
+}
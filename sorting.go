@@ -0,0 +1,70 @@
+package toyfloat
+
+// SortCodes sorts encoded values in ascending order.
+// The decoded sequence is the same as after sorting by Decode,
+// and -0 is placed right before +0.
+// It is a stable LSD radix sort on the comparable form,
+// so extra most-significant bits are ignored and preserved.
+func SortCodes(t *Type, codes []uint16) {
+	if len(codes) < 2 {
+		return
+	}
+
+	buffer := make([]uint16, len(codes))
+	src, dst := codes, buffer
+
+	for shift := uint8(0); shift < t.length; shift += 8 {
+		var offsets [257]int
+		for _, code := range src {
+			digit := (codeToComparable(code, t) >> shift) & 0xFF
+			offsets[digit+1]++
+		}
+
+		for i := 1; i < len(offsets); i++ {
+			offsets[i] += offsets[i-1]
+		}
+
+		for _, code := range src {
+			digit := (codeToComparable(code, t) >> shift) & 0xFF
+			dst[offsets[digit]] = code
+			offsets[digit]++
+		}
+
+		src, dst = dst, src
+	}
+
+	// The last pass has written to the buffer.
+	if &src[0] != &codes[0] {
+		copy(codes, src)
+	}
+}
+
+// SearchCodes returns the smallest index i in a slice sorted
+// by SortCodes at which the decoded value is at least v,
+// or len(sorted), if there is no such index.
+// It decodes one value regardless of the slice length.
+// NaN is searched the same way it is encoded, as zero by default.
+func SearchCodes(t *Type, sorted []uint16, v float64) int {
+	key := int(codeToComparable(t.Encode(v), t))
+
+	// Encode returns the nearest code, which may be below v.
+	if t.Decode(comparableToCode(uint16(key), t)) < v {
+		key++
+	}
+
+	// Both zeros are not less than v.
+	if (0 != t.minus) && (KeepNegativeZero == t.zeroMode) && (key == int(t.minus)) {
+		key--
+	}
+
+	lo, hi := 0, len(sorted)
+	for lo < hi {
+		middle := int(uint(lo+hi) >> 1)
+		if int(codeToComparable(sorted[middle], t)) < key {
+			lo = middle + 1
+		} else {
+			hi = middle
+		}
+	}
+	return lo
+}
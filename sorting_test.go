@@ -0,0 +1,125 @@
+package toyfloat
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func getSortingSample(length int, signed bool, t *testing.T) (Type, []uint16) {
+	tf := makeTypeX3(length, signed, t)
+
+	r := rand.New(rand.NewSource(int64(length)))
+	codes := make([]uint16, 5000)
+	for i := range codes {
+		codes[i] = uint16(r.Intn(int(tf.bitmask) + 1))
+	}
+
+	// Both zeros.
+	codes[0] = 0x0
+	codes[1] = tf.minus
+	return tf, codes
+}
+
+func TestSortCodes(t *testing.T) {
+	for _, length := range []int{5, 8, 9, 12, 16} {
+		for _, signed := range []bool{true, false} {
+			tf, codes := getSortingSample(length, signed, t)
+
+			expected := append([]uint16(nil), codes...)
+			sort.SliceStable(expected, func(i, j int) bool {
+				return tf.Decode(expected[i]) < tf.Decode(expected[j])
+			})
+
+			SortCodes(&tf, codes)
+
+			for i := range codes {
+				a := tf.Decode(codes[i])
+				b := tf.Decode(expected[i])
+				if a != b {
+					t.Fatalf("#%d: %f != %f (length %d, signed %t)",
+						i, a, b, length, signed)
+				}
+
+				if (i > 0) && (tf.ToComparable(codes[i-1]) >
+					tf.ToComparable(codes[i])) {
+					t.Fatalf("#%d: not sorted (length %d, signed %t)",
+						i, length, signed)
+				}
+			}
+		}
+	}
+}
+
+func TestSortCodesPreservesExtraBits(t *testing.T) {
+	tf := makeTypeX4(12, true, t)
+
+	codes := []uint16{
+		0xA000 | tf.Encode(3),
+		0x5000 | tf.Encode(-2),
+		0xF000 | tf.Encode(1)}
+
+	SortCodes(&tf, codes)
+
+	expected := []uint16{
+		0x5000 | tf.Encode(-2),
+		0xF000 | tf.Encode(1),
+		0xA000 | tf.Encode(3)}
+
+	for i := range codes {
+		if codes[i] != expected[i] {
+			t.Fatalf("#%d: 0x%X != 0x%X", i, codes[i], expected[i])
+		}
+	}
+}
+
+func TestSearchCodes(t *testing.T) {
+	for _, length := range []int{5, 8, 12} {
+		for _, signed := range []bool{true, false} {
+			tf, codes := getSortingSample(length, signed, t)
+			SortCodes(&tf, codes)
+
+			limit := tf.MaxValue() * 1.1
+			values := []float64{0, -0.0, 1, -1, limit, -limit, math.NaN()}
+			for x := -limit; x <= limit; x += limit / 1000 {
+				values = append(values, x)
+			}
+
+			for _, v := range values {
+				expected := sort.Search(len(codes), func(i int) bool {
+					if math.IsNaN(v) {
+						return tf.Decode(codes[i]) >= 0
+					}
+					return tf.Decode(codes[i]) >= v
+				})
+
+				result := SearchCodes(&tf, codes, v)
+				if result != expected {
+					t.Fatalf("%f: %d != %d (length %d, signed %t)",
+						v, result, expected, length, signed)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkSortCodes(b *testing.B) {
+	tf, e := NewTypeX4(12, true)
+	if e != nil {
+		b.Fatal(e)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	source := make([]uint16, 100000)
+	for i := range source {
+		source[i] = uint16(r.Intn(int(tf.bitmask) + 1))
+	}
+
+	codes := make([]uint16, len(source))
+	for i := 0; i < b.N; i++ {
+		copy(codes, source)
+		SortCodes(&tf, codes)
+	}
+	intResult = int(codes[0])
+}
@@ -0,0 +1,327 @@
+package toyfloat
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Spec returns a short textual form of the type,
+// which ParseType turns back into the same type.
+//
+// It is the sign ("s" for signed, "u" for unsigned), the length,
+// then the exponent size after "x", the base after "b"
+// and minX after "m". For example, NewTypeX4(12, true)
+// is "s12x4b2m-8", and NewType(8, 10, 3, -2, false) is "u8x3b10m-2".
+//
+// Options follow after "+". WithNonFinite is "+nonfinite",
+// and WithReserved is "+reserved=" with hexadecimal codes
+// separated by dots in ascending order, such as "+reserved=7fe.7ff".
+// WithNegativeZero is "+zero=canonical" or "+zero=marker",
+// and nothing for KeepNegativeZero. WithTwosComplement is "+twos".
+// WithLayout is "+layout=" with the field order, and ".msb"
+// for AlignMSB, such as "+layout=xms" or "+layout=sxm.msb".
+// WithTagPolicy is "+tags=clear" or "+tags=keep".
+// WithProtection is "+protect=parity", "+protect=hamming" or "+protect=crc4".
+func (t *Type) Spec() string {
+	return t.Params().String()
+}
+
+// ParseType makes a type from its textual form. See Type.Spec.
+func ParseType(spec string) (Type, error) {
+	p := specParser{spec: spec}
+
+	signToken := p.next("")
+	var signed bool
+	switch signToken.text {
+	case "s":
+		signed = true
+	case "u":
+		signed = false
+	default:
+		return Type{}, p.fail(signToken, "expected s or u")
+	}
+
+	length, lengthToken, err := p.number("")
+	if err != nil {
+		return Type{}, err
+	}
+
+	xSize, xToken, err := p.number("x")
+	if err != nil {
+		return Type{}, err
+	}
+
+	xBase, bToken, err := p.number("b")
+	if err != nil {
+		return Type{}, err
+	}
+
+	minX, mToken, err := p.signedNumber("m")
+	if err != nil {
+		return Type{}, err
+	}
+
+	params := Params{
+		Length: uint8(length),
+		XBase:  uint8(xBase),
+		XSize:  uint8(xSize),
+		MinX:   minX,
+		Signed: signed,
+	}
+
+	for p.offset < len(p.spec) {
+		option := p.option()
+		switch option.text {
+		case "+nonfinite":
+			params.NonFinite = true
+		case "+twos":
+			params.TwosComplement = true
+		case "+":
+			return Type{}, p.fail(option, "expected an option")
+		default:
+			if strings.HasPrefix(option.text, "+reserved=") {
+				codes, ok := parseReserved(option.text[len("+reserved="):])
+				if !ok {
+					return Type{}, p.fail(option, "expected hexadecimal codes")
+				}
+				params.Reserved = codes
+				continue
+			}
+
+			if strings.HasPrefix(option.text, "+layout=") {
+				layout, ok := parseLayout(option.text[len("+layout="):])
+				if !ok {
+					return Type{}, p.fail(option, "expected a field order")
+				}
+				params.Layout = layout
+				continue
+			}
+
+			if strings.HasPrefix(option.text, "+protect=") {
+				protection, ok := parseProtection(option.text[len("+protect="):])
+				if !ok {
+					return Type{}, p.fail(option, "expected parity, hamming or crc4")
+				}
+				params.Protection = protection
+				continue
+			}
+
+			if strings.HasPrefix(option.text, "+tags=") {
+				policy, ok := parseTagPolicy(option.text[len("+tags="):])
+				if !ok {
+					return Type{}, p.fail(option, "expected clear or keep")
+				}
+				params.Tags = policy
+				continue
+			}
+
+			if strings.HasPrefix(option.text, "+zero=") {
+				mode, ok := parseNegativeZero(option.text[len("+zero="):])
+				if !ok {
+					return Type{}, p.fail(option, "expected canonical or marker")
+				}
+				params.NegativeZero = mode
+				continue
+			}
+
+			if option.text[0] == '+' {
+				return Type{}, p.fail(option, "unknown option")
+			}
+			return Type{}, p.fail(option, "unexpected characters")
+		}
+	}
+
+	t, err := NewTypeFromParams(params)
+	if err != nil {
+		var token specToken
+		switch {
+		case errors.Is(err, ErrBaseOutOfRange):
+			token = bToken
+		case errors.Is(err, ErrMinExponentNotNegative), errors.Is(err, ErrMinExponentTooSmall):
+			token = mToken
+		case errors.Is(err, ErrLengthTooLarge), errors.Is(err, ErrNoMantissa):
+			token = lengthToken
+		case errors.Is(err, ErrInvalidNegativeZero),
+			errors.Is(err, ErrTwosComplementUnsigned),
+			errors.Is(err, ErrInvalidLayout),
+			errors.Is(err, ErrInvalidTagPolicy),
+			errors.Is(err, ErrInvalidProtection):
+			// The options do not suit the sign.
+			token = signToken
+		default:
+			// The exponent range depends on all of its parameters.
+			token = specToken{xToken.offset, spec[xToken.offset:]}
+		}
+		return Type{}, p.wrap(token, err)
+	}
+	return t, nil
+}
+
+type specToken struct {
+	offset int
+	text   string
+}
+
+type specParser struct {
+	spec   string
+	offset int
+}
+
+// next reads the prefix and the digits that follow it.
+// An empty prefix means a single non-digit character,
+// or nothing at the end of the string.
+func (p *specParser) next(prefix string) specToken {
+	start := p.offset
+	if prefix == "" {
+		if (p.offset < len(p.spec)) && !isDigit(p.spec[p.offset]) {
+			p.offset++
+			return specToken{start, p.spec[start:p.offset]}
+		}
+	} else if strings.HasPrefix(p.spec[p.offset:], prefix) {
+		p.offset += len(prefix)
+		if (p.offset < len(p.spec)) && (p.spec[p.offset] == '-') {
+			p.offset++
+		}
+	} else {
+		// Any unexpected letter is the token.
+		if p.offset < len(p.spec) {
+			p.offset++
+		}
+		return specToken{start, p.spec[start:p.offset]}
+	}
+
+	for (p.offset < len(p.spec)) && isDigit(p.spec[p.offset]) {
+		p.offset++
+	}
+	return specToken{start, p.spec[start:p.offset]}
+}
+
+// option reads "+", the letters that follow it, and the value after "=".
+// Anything else is read as a single character.
+func (p *specParser) option() specToken {
+	start := p.offset
+	p.offset++
+	if p.spec[start] == '+' {
+		for (p.offset < len(p.spec)) && isLetter(p.spec[p.offset]) {
+			p.offset++
+		}
+
+		if (p.offset < len(p.spec)) && (p.spec[p.offset] == '=') {
+			p.offset++
+			for (p.offset < len(p.spec)) && (p.spec[p.offset] != '+') {
+				p.offset++
+			}
+		}
+	}
+	return specToken{start, p.spec[start:p.offset]}
+}
+
+// parseReserved reads codes in the form they are written by Params.String.
+func parseReserved(value string) ([]Sentinel, bool) {
+	var codes []Sentinel
+	for _, hex := range strings.Split(value, ".") {
+		code, err := strconv.ParseUint(hex, 16, 16)
+		if (err != nil) || (hex != strconv.FormatUint(code, 16)) {
+			return nil, false
+		}
+
+		n := len(codes)
+		if (n > 0) && (Sentinel(code) <= codes[n-1]) {
+			return nil, false
+		}
+		codes = append(codes, Sentinel(code))
+	}
+	return codes, true
+}
+
+// parseLayout reads layouts written by Params.String,
+// except the default one, which is not written.
+func parseLayout(value string) (Layout, bool) {
+	var layout Layout
+	if strings.HasSuffix(value, ".msb") {
+		layout.AlignMSB = true
+		value = value[:len(value)-len(".msb")]
+	}
+
+	for i, name := range fieldOrderNames {
+		if value == name {
+			layout.Order = FieldOrder(i)
+			return layout, (Layout{}) != layout
+		}
+	}
+	return Layout{}, false
+}
+
+// parseProtection reads the protections written by Params.String.
+func parseProtection(value string) (Protection, bool) {
+	for _, protection := range []Protection{Parity, Hamming, CRC4} {
+		if value == protection.String() {
+			return protection, true
+		}
+	}
+	return NoProtection, false
+}
+
+// parseTagPolicy reads the policies written by Params.String.
+func parseTagPolicy(value string) (TagPolicy, bool) {
+	for _, policy := range []TagPolicy{TagsCleared, TagsPreserved} {
+		if value == policy.String() {
+			return policy, true
+		}
+	}
+	return TagsIgnored, false
+}
+
+// parseNegativeZero reads the modes written by Params.String.
+func parseNegativeZero(value string) (NegativeZeroMode, bool) {
+	for _, mode := range []NegativeZeroMode{CanonicalZero, NegativeZeroMarker} {
+		if value == mode.String() {
+			return mode, true
+		}
+	}
+	return KeepNegativeZero, false
+}
+
+func (p *specParser) number(prefix string) (int, specToken, error) {
+	n, token, err := p.signedNumber(prefix)
+	if (err == nil) && ((n < 0) || (n > 255)) {
+		err = p.fail(token, "number out of range")
+	}
+	return n, token, err
+}
+
+func (p *specParser) signedNumber(prefix string) (int, specToken, error) {
+	token := p.next(prefix)
+	if !strings.HasPrefix(token.text, prefix) {
+		return 0, token, p.fail(token, "expected "+strconv.Quote(prefix))
+	}
+
+	n, err := strconv.Atoi(token.text[len(prefix):])
+	if err != nil {
+		return 0, token, p.fail(token, "expected a number")
+	}
+	return n, token, nil
+}
+
+func (p *specParser) fail(token specToken, msg string) error {
+	text := token.text
+	if text == "" {
+		text = "end of string"
+	}
+	return fmt.Errorf("spec %q, %q at %d: %s", p.spec, text, token.offset, msg)
+}
+
+// wrap keeps the error visible to errors.Is and errors.As.
+func (p *specParser) wrap(token specToken, err error) error {
+	return fmt.Errorf("spec %q, %q at %d: %w", p.spec, token.text, token.offset, err)
+}
+
+func isLetter(c byte) bool {
+	return ('a' <= c) && (c <= 'z')
+}
+
+func isDigit(c byte) bool {
+	return ('0' <= c) && (c <= '9')
+}
@@ -0,0 +1,128 @@
+package toyfloat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSpecRoundTrip(t *testing.T) {
+	for _, spec := range []string{
+		"s12x4b2m-8", "u12x4b2m-8", "s15x3b2m-6",
+		"u3x2b3m-3", "s8x3b10m-2", "s16x9b10m-256"} {
+
+		tf, err := ParseType(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tf.Spec() != spec {
+			t.Fatalf("%s != %s", tf.Spec(), spec)
+		}
+
+		same, err := ParseType(tf.Spec())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if same != tf {
+			t.Fatalf("%s: different types", spec)
+		}
+	}
+
+	for name := range defaultRegistry.names {
+		tf, _ := Lookup(name)
+		same, err := ParseType(tf.Spec())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if same != tf {
+			t.Fatalf("%s: different types", name)
+		}
+	}
+}
+
+func TestSpecExamples(t *testing.T) {
+	a, err := ParseType("s12x4b2m-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a != makeTypeX4(12, true, t) {
+		t.Fatalf("s12x4b2m-8 is not NewTypeX4(12, true)")
+	}
+
+	b, err := ParseType("u8x3b10m-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := NewType(8, 10, 3, -2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b != expected {
+		t.Fatalf("u8x3b10m-2 is not NewType(8, 10, 3, -2, false)")
+	}
+}
+
+func TestSpecErrors(t *testing.T) {
+	tests := []struct {
+		spec, token, message string
+	}{
+		{"", "end of string", "expected s or u"},
+		{"12x4b2m-8", "12", "expected s or u"},
+		{"s", "end of string", "expected a number"},
+		{"sx4b2m-8", "x", "expected a number"},
+		{"s12b2m-8", "b", `expected "x"`},
+		{"s12x4m-8", "m", `expected "b"`},
+		{"s12x4b2", "end of string", `expected "m"`},
+		{"s12x4b2m", "m", "expected a number"},
+		{"s12x4b2m-8z", "z", "unexpected characters"},
+		{"s12x4b2m-8 ", " ", "unexpected characters"},
+		{"s300x4b2m-8", "300", "number out of range"},
+		{"s12x4b11m-8", "b11", ErrBaseOutOfRange.Error()},
+		{"s12x4b2m8", "m8", ErrMinExponentNotNegative.Error()},
+		{"u8x2b2m-9223372036854775808", "m-9223372036854775808", ErrMinExponentTooSmall.Error()},
+		{"s12x4b10m-309", "m-309", ErrMinExponentTooSmall.Error()},
+		{"s17x4b2m-8", "17", ErrLengthTooLarge.Error()},
+		{"s5x4b2m-8", "5", ErrNoMantissa.Error()},
+		{"s16x10b3m-1", "x10b3m-1", ErrRangeExceedsFloat64.Error()},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseType(tt.spec)
+		if err == nil {
+			t.Fatalf("%q: error expected", tt.spec)
+		}
+
+		msg := err.Error()
+		if !strings.Contains(msg, `"`+tt.token+`"`) ||
+			!strings.Contains(msg, tt.message) {
+			t.Fatalf("%q: unexpected error: %s", tt.spec, msg)
+		}
+	}
+}
+
+func TestSpecNegativeExponents(t *testing.T) {
+	// All the exponents are negative, since -minX > 2^xSize.
+	for _, spec := range []string{"u8x2b2m-100", "s12x0b2m-8"} {
+		tf, err := ParseType(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		maxValue := tf.MaxValue()
+		if !(maxValue > 0) || (maxValue >= 1) {
+			t.Fatalf("%s: %v", spec, maxValue)
+		}
+
+		for i := 0; i < 1<<tf.length; i++ {
+			v := tf.Decode(uint16(i))
+			if w := tf.Decode(tf.Encode(v)); v != w {
+				t.Fatalf("%s: 0x%X: %v != %v", spec, i, v, w)
+			}
+		}
+	}
+}
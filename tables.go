@@ -0,0 +1,66 @@
+package toyfloat
+
+import "sync"
+
+// lookupTables are built on first use and shared by copies of a type.
+// Once built, they are never modified.
+type lookupTables struct {
+	decodeOnce sync.Once
+	decoded    []float64
+
+	productOnce sync.Once
+	products    []float64
+}
+
+// productTableMaxLength limits pairwise product tables to 2^16 entries.
+const productTableMaxLength = 8
+
+// invalidTypeTable is the table of the zero Type,
+// which has a zero bitmask, and decodes everything to zero.
+var invalidTypeTable = []float64{0.0}
+
+// decodeTable returns decoded values of all codes without extra bits.
+// Index it with t.index(code).
+func (t *Type) decodeTable() []float64 {
+	if nil == t.data {
+		return invalidTypeTable
+	}
+
+	t.data.tables.decodeOnce.Do(func() {
+		table := make([]float64, int(t.bitmask)+1)
+		for i := range table {
+			table[i] = decode(uint16(i)<<t.align, t)
+		}
+		t.data.tables.decoded = table
+	})
+	return t.data.tables.decoded
+}
+
+// index returns the position of the value bits of a code,
+// which are the low ones, unless the layout is aligned to MSB.
+func (t *Type) index(code uint16) uint16 {
+	return (code >> t.align) & t.bitmask
+}
+
+// productTable returns products of decoded values of all pairs of codes.
+// Index it with t.index(a)<<t.length | t.index(b).
+// It must not be used for types longer than productTableMaxLength.
+func (t *Type) productTable() []float64 {
+	if nil == t.data {
+		// Its length is zero, so the index is always zero.
+		return invalidTypeTable
+	}
+
+	t.data.tables.productOnce.Do(func() {
+		decoded := t.decodeTable()
+		table := make([]float64, len(decoded)*len(decoded))
+		for a, va := range decoded {
+			row := table[a<<t.length:]
+			for b, vb := range decoded {
+				row[b] = va * vb
+			}
+		}
+		t.data.tables.products = table
+	})
+	return t.data.tables.products
+}
@@ -0,0 +1,104 @@
+package toyfloat
+
+// TagPolicy selects what the functions that return codes
+// do with the tag of their argument. See WithTagPolicy.
+type TagPolicy uint8
+
+const (
+	// TagsIgnored is the default: extra bits are not maintained.
+	// Abs keeps them for the default layout, ToComparable clears them,
+	// FromComparable and UseIntegerDelta may set them.
+	TagsIgnored TagPolicy = iota
+
+	// TagsCleared makes Abs, FromComparable and UseIntegerDelta
+	// return codes without a tag, the way Encode does.
+	TagsCleared
+
+	// TagsPreserved makes Abs keep the tag of its argument,
+	// and UseIntegerDelta keep the tag of the last code.
+	// ToComparable places the tag above the comparable form,
+	// and FromComparable takes it from there.
+	TagsPreserved
+)
+
+// String returns the name of the policy, as in a spec.
+func (p TagPolicy) String() string {
+	switch p {
+	case TagsIgnored:
+		return "ignore"
+	case TagsCleared:
+		return "clear"
+	case TagsPreserved:
+		return "keep"
+	}
+	return "invalid"
+}
+
+// WithTagPolicy selects what happens to tags, which are stored
+// in the extra bits of codes. GetIntegerDelta ignores tags anyway.
+func WithTagPolicy(policy TagPolicy) Option {
+	return func(p *Params) {
+		p.Tags = policy
+	}
+}
+
+// TagBits returns the number of extra bits, which is 16 minus the length.
+// They are the most-significant bits, unless the layout is aligned to MSB.
+func (t *Type) TagBits() uint8 {
+	return 16 - t.length
+}
+
+// WithTag stores the tag in the extra bits of the code,
+// replacing the ones that were there.
+// Bits of the tag that do not fit into TagBits are dropped.
+//
+// Decode ignores the tag. For two's complement codes
+// the extra bits are the sign extension, so a tagged code
+// cannot be compared as int16.
+func (t *Type) WithTag(code, tag uint16) uint16 {
+	spare := t.spareMask()
+	return (code &^ spare) | ((tag << t.tagShift()) & spare)
+}
+
+// Tag returns the extra bits of the code.
+func (t *Type) Tag(code uint16) uint16 {
+	return (code & t.spareMask()) >> t.tagShift()
+}
+
+// StripTag returns the code the way Encode would return it.
+func (t *Type) StripTag(code uint16) uint16 {
+	code &^= t.spareMask()
+	if t.twos && (0 == t.align) {
+		return signExtend(code, t)
+	}
+	return code
+}
+
+// ----------------
+
+func (t *Type) spareMask() uint16 {
+	return ^(t.bitmask << t.align)
+}
+
+func (t *Type) tagShift() uint8 {
+	if 0 != t.align {
+		return 0
+	}
+	return t.length
+}
+
+// applyTagPolicy gives the result the tag of the argument, if required,
+// or the check bits of the result.
+func applyTagPolicy(result, argument uint16, t *Type) uint16 {
+	if NoProtection != t.protection {
+		return t.Protect(result)
+	}
+
+	switch t.tags {
+	case TagsCleared:
+		return t.StripTag(result)
+	case TagsPreserved:
+		return t.WithTag(result, t.Tag(argument))
+	}
+	return result
+}
@@ -0,0 +1,150 @@
+package toyfloat
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	tf := makeTypeX4(13, true, t)
+	if tf.TagBits() != 3 {
+		t.Fatalf("%d != 3", tf.TagBits())
+	}
+
+	for f := -255.0; f <= 255.0; f += 0.1 {
+		code := tf.Encode(f)
+		if tf.Tag(code) != 0 {
+			t.Fatalf("%f -> 0x%X has a tag", f, code)
+		}
+
+		for tag := uint16(0); tag < 8; tag++ {
+			tagged := tf.WithTag(code, tag)
+			if tf.Tag(tagged) != tag {
+				t.Fatalf("0x%X: %d != %d", tagged, tf.Tag(tagged), tag)
+			} else if tf.StripTag(tagged) != code {
+				t.Fatalf("0x%X != 0x%X", tf.StripTag(tagged), code)
+			} else if tf.Decode(tagged) != tf.Decode(code) {
+				t.Fatalf("0x%X: the tag changes the value", tagged)
+			} else if tf.WithTag(tagged, 0) != code {
+				t.Fatalf("0x%X: the tag is not replaced", tagged)
+			}
+		}
+	}
+
+	// The tag is truncated.
+	if tagged := tf.WithTag(0x123, 0xF); tagged != 0xE123 {
+		t.Fatalf("0x%X != 0xE123", tagged)
+	}
+
+	msb, err := NewTypeX4(13, true, WithLayout(Layout{AlignMSB: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := msb.Encode(-3)
+	if tagged := msb.WithTag(code, 5); (tagged != code|5) || (msb.Tag(tagged) != 5) {
+		t.Fatalf("0x%X: the tag must be in the low bits", tagged)
+	}
+
+	twos, err := NewTypeX4(13, true, WithTwosComplement())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code = twos.Encode(-3)
+	if stripped := twos.StripTag(twos.WithTag(code, 2)); stripped != code {
+		t.Fatalf("0x%X != 0x%X, the sign extension must be restored", stripped, code)
+	}
+}
+
+func TestTagPolicy(t *testing.T) {
+	ignored := makeTypeX4(13, true, t, WithTagPolicy(TagsIgnored))
+	if ignored != makeTypeX4(13, true, t) {
+		t.Fatalf("TagsIgnored is the default")
+	}
+
+	cleared := makeTypeX4(13, true, t, WithTagPolicy(TagsCleared))
+	kept := makeTypeX4(13, true, t, WithTagPolicy(TagsPreserved))
+
+	for f := -255.0; f <= 255.0; f += 0.1 {
+		code := kept.Encode(f)
+		tagged := kept.WithTag(code, 5)
+		abs := kept.StripTag(kept.Abs(code))
+
+		if result := kept.Abs(tagged); result != kept.WithTag(abs, 5) {
+			t.Fatalf("%f: Abs(0x%X) = 0x%X", f, tagged, result)
+		} else if result := cleared.Abs(tagged); result != abs {
+			t.Fatalf("%f: Abs(0x%X) = 0x%X", f, tagged, result)
+		}
+
+		c := kept.ToComparable(tagged)
+		if c != ignored.ToComparable(code)|5<<13 {
+			t.Fatalf("%f: 0x%X -> comparable 0x%X", f, tagged, c)
+		} else if back := kept.FromComparable(c); back != tagged {
+			t.Fatalf("%f: 0x%X -> 0x%X -> 0x%X", f, tagged, c, back)
+		}
+
+		c = cleared.ToComparable(tagged)
+		if back := cleared.FromComparable(c); back != code {
+			t.Fatalf("%f: 0x%X -> 0x%X", f, tagged, back)
+		}
+	}
+
+	last := kept.WithTag(kept.Encode(-256), 1)
+	for x := -256.0; x <= 256.0; x += 0.01 {
+		code := kept.WithTag(kept.Encode(x), 6)
+
+		delta := kept.GetIntegerDelta(last, code)
+		if delta != ignored.GetIntegerDelta(kept.StripTag(last), kept.StripTag(code)) {
+			t.Fatalf("%f: tags must not change deltas", x)
+		}
+
+		// The tag of the last code.
+		if result := kept.UseIntegerDelta(last, delta); result != kept.WithTag(code, 1) {
+			t.Fatalf("%f: 0x%X + %d = 0x%X", x, last, delta, result)
+		}
+
+		if result := cleared.UseIntegerDelta(last, delta); result != kept.StripTag(code) {
+			t.Fatalf("%f: 0x%X + %d = 0x%X", x, last, delta, result)
+		}
+
+		last = kept.WithTag(code, 1)
+	}
+}
+
+func TestTagPolicyParams(t *testing.T) {
+	for _, policy := range []TagPolicy{TagsCleared, TagsPreserved} {
+		tf := makeTypeX4(13, true, t, WithTagPolicy(policy))
+		if tf.Params().Tags != policy {
+			t.Fatalf("%s != %s", tf.Params().Tags, policy)
+		}
+
+		parsed, err := ParseType(tf.Spec())
+		if err != nil {
+			t.Fatal(err)
+		} else if parsed != tf {
+			t.Fatalf("%s: different types", tf.Spec())
+		}
+	}
+
+	kept := makeTypeX4(13, true, t, WithTagPolicy(TagsPreserved))
+	if spec := kept.Spec(); spec != "s13x4b2m-8+tags=keep" {
+		t.Fatalf("%s != s13x4b2m-8+tags=keep", spec)
+	}
+
+	_, err := NewTypeX4(13, true, WithTagPolicy(TagsPreserved+1))
+	if !errors.Is(err, ErrInvalidTagPolicy) {
+		t.Fatalf("ErrInvalidTagPolicy expected, got %v", err)
+	}
+
+	_, err = NewTypeX4(13, true, WithTwosComplement(), WithTagPolicy(TagsPreserved))
+	if !errors.Is(err, ErrInvalidTagPolicy) {
+		t.Fatalf("ErrInvalidTagPolicy expected, got %v", err)
+	}
+
+	for _, bad := range []string{"s13x4b2m-8+tags=", "s13x4b2m-8+tags=ignore"} {
+		if _, err := ParseType(bad); err == nil {
+			t.Fatalf("%s: error expected", bad)
+		}
+	}
+}
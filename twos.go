@@ -0,0 +1,54 @@
+package toyfloat
+
+// WithTwosComplement makes a signed type that encodes numbers
+// as two's complement integers ordered by value.
+// Codes are sign-extended to 16 bits, so converted to int16 they
+// can be compared and subtracted directly: the difference is
+// the same as GetIntegerDelta returns, unless the type is made WithReserved,
+// since GetIntegerDelta does not count the reserved codes between them.
+//
+// The comparable form is the same as for the sign–magnitude type,
+// and -0 is -1, right before +0, unless WithNegativeZero says otherwise.
+// Extra most-significant bits are ignored by Decode, as usual.
+// WithTagPolicy(TagsPreserved) and WithProtection need the layout
+// aligned to the most-significant bit, to keep the sign extension.
+func WithTwosComplement() Option {
+	return func(p *Params) {
+		p.TwosComplement = true
+	}
+}
+
+// ToSignMagnitude converts a code of this type to the sign–magnitude
+// layout of the type made without WithTwosComplement.
+// The result has no extra bits.
+// For sign–magnitude types, it returns the code without extra bits.
+func (t *Type) ToSignMagnitude(x uint16) uint16 {
+	return fromLayout(x, t) & t.bitmask
+}
+
+// FromSignMagnitude is ToSignMagnitude in reverse.
+// Extra bits of the argument are ignored.
+func (t *Type) FromSignMagnitude(x uint16) uint16 {
+	return toLayout(x&t.bitmask, t)
+}
+
+// ----------------
+
+// twosToSignMagnitude expects a code without extra bits.
+func twosToSignMagnitude(x uint16, t *Type) uint16 {
+	return fromComparable((x^t.minus)&t.bitmask, t) & t.bitmask
+}
+
+// signMagnitudeToTwos expects a code without extra bits.
+func signMagnitudeToTwos(x uint16, t *Type) uint16 {
+	return signExtend(toComparable(x, t)^t.minus, t)
+}
+
+// signExtend copies the sign bit to the extra bits.
+func signExtend(x uint16, t *Type) uint16 {
+	x &= t.bitmask
+	if 0 != x&t.minus {
+		return x | ^t.bitmask
+	}
+	return x
+}
@@ -0,0 +1,272 @@
+package toyfloat
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// twosPairs returns signed types with and without WithTwosComplement.
+func twosPairs(t *testing.T) [][2]Type {
+	optionSets := [][]Option{
+		nil,
+		{WithNonFinite()},
+		{WithNegativeZero(CanonicalZero)},
+		{WithNegativeZero(NegativeZeroMarker)},
+		{WithReserved(0x7FF, 0x448)},
+	}
+
+	var pairs [][2]Type
+	for _, options := range optionSets {
+		for _, p := range []Params{
+			{Length: 12, XBase: 2, XSize: 4, MinX: -8},
+			{Length: 16, XBase: 2, XSize: 4, MinX: -8},
+			{Length: 13, XBase: 2, XSize: 3, MinX: -6},
+			{Length: 5, XBase: 3, XSize: 2, MinX: -3},
+			{Length: 8, XBase: 10, XSize: 3, MinX: -2},
+		} {
+			plain, err := NewType(p.Length, p.XBase, p.XSize, p.MinX, true, options...)
+			if errors.Is(err, ErrInvalidReservedCode) {
+				// The codes do not fit into short types.
+				continue
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			twos, err := NewType(p.Length, p.XBase, p.XSize, p.MinX, true,
+				append(options, WithTwosComplement())...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pairs = append(pairs, [2]Type{plain, twos})
+		}
+	}
+	return pairs
+}
+
+func sameFloat(a, b float64) bool {
+	return (a == b) || (math.IsNaN(a) && math.IsNaN(b))
+}
+
+func TestTwosComplementDecoding(t *testing.T) {
+	for _, pair := range twosPairs(t) {
+		plain, twos := pair[0], pair[1]
+
+		for i := 0; i <= int(plain.bitmask); i++ {
+			code := uint16(i)
+			if (CanonicalZero == twos.zeroMode) && plain.IsNegativeZero(code) {
+				// There is no such code in two's complement.
+				continue
+			}
+
+			converted := twos.FromSignMagnitude(code)
+			if back := twos.ToSignMagnitude(converted); back != code {
+				t.Fatalf("%s: 0x%X -> 0x%X -> 0x%X", twos.Spec(), code, converted, back)
+			}
+
+			if !sameFloat(twos.Decode(converted), plain.Decode(code)) {
+				t.Fatalf("%s: 0x%X: %g != %g", twos.Spec(), code,
+					twos.Decode(converted), plain.Decode(code))
+			}
+
+			// Decode ignores extra bits.
+			if !sameFloat(twos.Decode(converted&twos.bitmask), twos.Decode(converted)) {
+				t.Fatalf("%s: 0x%X depends on extra bits", twos.Spec(), converted)
+			}
+
+			if twos.IsReserved(converted) != plain.IsReserved(code) {
+				t.Fatalf("%s: 0x%X reserved", twos.Spec(), code)
+			}
+
+			if twos.IsNegativeZero(converted) != plain.IsNegativeZero(code) {
+				t.Fatalf("%s: 0x%X negative zero", twos.Spec(), code)
+			}
+
+			if abs := twos.ToSignMagnitude(twos.Abs(converted)); abs != plain.Abs(code) {
+				t.Fatalf("%s: Abs(0x%X) = 0x%X", twos.Spec(), code, abs)
+			}
+		}
+	}
+}
+
+func TestTwosComplementEncoding(t *testing.T) {
+	for _, pair := range twosPairs(t) {
+		plain, twos := pair[0], pair[1]
+		step := plain.MaxValue() / 20000
+
+		values := []float64{0, 1, -1, math.NaN(), math.Inf(+1), math.Inf(-1),
+			plain.MaxValue() * 2, plain.MinValue() * 2, math.Copysign(0, -1)}
+		for f := plain.MinValue(); f <= plain.MaxValue(); f += step {
+			values = append(values, f)
+		}
+
+		for _, f := range values {
+			code := twos.Encode(f)
+			expected := twos.FromSignMagnitude(plain.Encode(f))
+			if code != expected {
+				t.Fatalf("%s: %f -> 0x%X, expected 0x%X", twos.Spec(), f, code, expected)
+			}
+
+			// Sign-extended.
+			if (int16(code) < 0) != (0 != code&twos.minus) {
+				t.Fatalf("%s: %f -> 0x%X is not sign-extended", twos.Spec(), f, code)
+			}
+		}
+	}
+}
+
+func TestTwosComplementOrdering(t *testing.T) {
+	for _, pair := range twosPairs(t) {
+		plain, twos := pair[0], pair[1]
+
+		for i := 0; i <= int(twos.bitmask); i++ {
+			c := uint16(i)
+			code := twos.FromComparable(c)
+
+			if twos.ToComparable(code) != c {
+				t.Fatalf("%s: comparable 0x%X -> 0x%X", twos.Spec(), c, code)
+			}
+
+			// The same comparable form as the sign-magnitude type.
+			if twos.ToSignMagnitude(code) != plain.FromComparable(c)&plain.bitmask {
+				t.Fatalf("%s: comparable 0x%X", twos.Spec(), c)
+			}
+
+			// int16 order is the order of comparable forms.
+			if int(int16(code)) != i-int(twos.minus) {
+				t.Fatalf("%s: comparable 0x%X -> %d", twos.Spec(), c, int16(code))
+			}
+		}
+
+		last := twos.Encode(twos.MinValue())
+		for x := twos.MinValue(); x <= twos.MaxValue(); x += twos.MaxValue() / 5000 {
+			code := twos.Encode(x)
+			if int16(code) < int16(last) {
+				t.Fatalf("%s: %f -> %d < %d", twos.Spec(), x, int16(code), int16(last))
+			}
+
+			delta := twos.GetIntegerDelta(last, code)
+			if (len(twos.Reserved()) == 0) && (delta != int(int16(code)-int16(last))) {
+				t.Fatalf("%s: %d - %d != %d", twos.Spec(), int16(code), int16(last), delta)
+			}
+
+			if delta != plain.GetIntegerDelta(twos.ToSignMagnitude(last),
+				twos.ToSignMagnitude(code)) {
+				t.Fatalf("%s: delta %d", twos.Spec(), delta)
+			}
+
+			if result := twos.UseIntegerDelta(last, delta); result != code {
+				t.Fatalf("%s: 0x%X + %d = 0x%X, expected 0x%X",
+					twos.Spec(), last, delta, result, code)
+			}
+			last = code
+		}
+
+		// Saturation.
+		max := twos.UseIntegerDelta(twos.Encode(0), 1<<20)
+		if max != twos.FromSignMagnitude(plain.UseIntegerDelta(0, 1<<20)) {
+			t.Fatalf("%s: 0x%X", twos.Spec(), max)
+		}
+	}
+}
+
+func TestTwosComplementCodes(t *testing.T) {
+	tf, err := NewTypeX4(12, true, WithTwosComplement())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		v    float64
+		code uint16
+	}{
+		{0, 0},
+		{math.Copysign(0, -1), 0},
+		{-1e-9, 0xFFFF}, // -0
+		{tf.MaxValue(), 0x7FF},
+		{tf.MinValue(), 0xF800},
+	}
+
+	for _, tt := range tests {
+		if code := tf.Encode(tt.v); code != tt.code {
+			t.Fatalf("%g -> 0x%X, expected 0x%X", tt.v, code, tt.code)
+		}
+	}
+
+	codes := []uint16{tf.Encode(3), tf.Encode(-2), tf.Encode(0.5), tf.Encode(-100)}
+	SortCodes(&tf, codes)
+	for i := 1; i < len(codes); i++ {
+		if int16(codes[i-1]) >= int16(codes[i]) {
+			t.Fatalf("wrong order %X", codes)
+		}
+	}
+
+	if min := Min(&tf, codes); min != tf.Decode(tf.Encode(-100)) {
+		t.Fatalf("Min: %f", min)
+	}
+
+	if i := SearchCodes(&tf, codes, 0); i != 2 {
+		t.Fatalf("%d != 2", i)
+	}
+}
+
+func TestTwosComplementWidening(t *testing.T) {
+	narrow, err := NewTypeX4(12, true, WithTwosComplement())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wide, err := NewTypeX4(16, true, WithTwosComplement())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain := makeTypeX4(16, true, t)
+	if IsCompatible(&plain, &wide) {
+		t.Fatalf("different layouts must not be compatible")
+	}
+
+	for f := -255.0; f <= 255.0; f += 0.01 {
+		code := narrow.Encode(f)
+		widened, err := Widen(code, &narrow, &wide)
+		if err != nil {
+			t.Fatal(err)
+		} else if wide.Decode(widened) != narrow.Decode(code) {
+			t.Fatalf("%f: 0x%X -> 0x%X", f, code, widened)
+		}
+
+		back, err := Narrow(widened, &wide, &narrow, Truncate)
+		if err != nil {
+			t.Fatal(err)
+		} else if back != code {
+			t.Fatalf("%f: 0x%X -> 0x%X", f, widened, back)
+		}
+	}
+}
+
+func TestTwosComplementParams(t *testing.T) {
+	const spec = "s12x4b2m-8+zero=marker+twos"
+	tf, err := ParseType(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !tf.Params().TwosComplement || (tf.Spec() != spec) {
+		t.Fatalf("%s: %+v", tf.Spec(), tf.Params())
+	}
+
+	// The marker is the lowest int16.
+	if marker, ok := tf.Marker(); !ok || (marker != 0xF800) {
+		t.Fatalf("0x%X, %t", marker, ok)
+	}
+
+	_, err = NewTypeX4(12, false, WithTwosComplement())
+	if !errors.Is(err, ErrTwosComplementUnsigned) {
+		t.Fatalf("ErrTwosComplementUnsigned expected, got %v", err)
+	}
+
+	if _, err := ParseType("u12x4b2m-8+twos"); !errors.Is(err, ErrTwosComplementUnsigned) {
+		t.Fatalf("ErrTwosComplementUnsigned expected, got %v", err)
+	}
+}
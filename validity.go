@@ -0,0 +1,41 @@
+package toyfloat
+
+// IsValid reports whether the type was made by a constructor.
+// The zero Type is not valid.
+func (t *Type) IsValid() bool {
+	return (nil != t) && (nil != t.data)
+}
+
+// TryEncode is Encode that returns ErrInvalidType for the zero Type.
+func (t *Type) TryEncode(v float64) (uint16, error) {
+	if !t.IsValid() {
+		return 0, ErrInvalidType
+	}
+	return encode(v, t), nil
+}
+
+// TryDecode is Decode that returns ErrInvalidType for the zero Type.
+func (t *Type) TryDecode(x uint16) (float64, error) {
+	if !t.IsValid() {
+		return 0, ErrInvalidType
+	}
+	return decode(x, t), nil
+}
+
+// TryGetIntegerDelta is GetIntegerDelta
+// that returns ErrInvalidType for the zero Type.
+func (t *Type) TryGetIntegerDelta(last uint16, x uint16) (int, error) {
+	if !t.IsValid() {
+		return 0, ErrInvalidType
+	}
+	return encodeDelta(last, x, t), nil
+}
+
+// TryUseIntegerDelta is UseIntegerDelta
+// that returns ErrInvalidType for the zero Type.
+func (t *Type) TryUseIntegerDelta(last uint16, delta int) (uint16, error) {
+	if !t.IsValid() {
+		return 0, ErrInvalidType
+	}
+	return decodeDelta(last, delta, t), nil
+}
@@ -0,0 +1,97 @@
+package toyfloat
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestZeroType(t *testing.T) {
+	zero := Type{}
+
+	if zero.IsValid() {
+		t.Fatalf("the zero Type must not be valid")
+	}
+
+	var nilType *Type
+	if nilType.IsValid() {
+		t.Fatalf("nil must not be valid")
+	}
+
+	valid := makeTypeX4(12, true, t)
+	if !valid.IsValid() {
+		t.Fatalf("a constructed type must be valid")
+	}
+
+	for _, v := range []float64{0, 1, -1, 1e300, math.Inf(-1), math.NaN()} {
+		if code := zero.Encode(v); code != 0 {
+			t.Fatalf("%f -> 0x%X", v, code)
+		}
+	}
+
+	for _, code := range []uint16{0, 1, 0x800, 0xFFFF} {
+		if v := zero.Decode(code); v != 0 {
+			t.Fatalf("0x%X -> %f", code, v)
+		}
+
+		if c := zero.ToComparable(code); c != 0 {
+			t.Fatalf("0x%X -> 0x%X", code, c)
+		}
+	}
+
+	if zero.GetIntegerDelta(3, 5) != 0 || zero.UseIntegerDelta(3, 5) != 0 {
+		t.Fatalf("delta must be zero")
+	}
+
+	codes := []uint16{1, 2, 3}
+	if Sum(&zero, codes) != 0 || Max(&zero, codes) != 0 ||
+		Dot(&zero, codes, codes) != 0 {
+		t.Fatalf("slice functions must see zeros")
+	}
+
+	SortCodes(&zero, codes)
+	SearchCodes(&zero, codes, 1)
+	EncodeSliceParallel(&zero, codes, []float64{1, 2, 3}, 2)
+}
+
+func TestTryMethods(t *testing.T) {
+	zero := Type{}
+
+	if _, err := zero.TryEncode(1); !errors.Is(err, ErrInvalidType) {
+		t.Fatalf("ErrInvalidType expected, got %v", err)
+	}
+
+	if _, err := zero.TryDecode(1); !errors.Is(err, ErrInvalidType) {
+		t.Fatalf("ErrInvalidType expected, got %v", err)
+	}
+
+	if _, err := zero.TryGetIntegerDelta(1, 2); !errors.Is(err, ErrInvalidType) {
+		t.Fatalf("ErrInvalidType expected, got %v", err)
+	}
+
+	if _, err := zero.TryUseIntegerDelta(1, 2); !errors.Is(err, ErrInvalidType) {
+		t.Fatalf("ErrInvalidType expected, got %v", err)
+	}
+
+	tf := makeTypeX4(12, true, t)
+
+	code, err := tf.TryEncode(1.567)
+	if (err != nil) || (code != tf.Encode(1.567)) {
+		t.Fatalf("0x%X, %v", code, err)
+	}
+
+	v, err := tf.TryDecode(code)
+	if (err != nil) || (v != tf.Decode(code)) {
+		t.Fatalf("%f, %v", v, err)
+	}
+
+	delta, err := tf.TryGetIntegerDelta(0, code)
+	if (err != nil) || (delta != tf.GetIntegerDelta(0, code)) {
+		t.Fatalf("%d, %v", delta, err)
+	}
+
+	next, err := tf.TryUseIntegerDelta(0, delta)
+	if (err != nil) || (next != code) {
+		t.Fatalf("0x%X, %v", next, err)
+	}
+}
@@ -0,0 +1,94 @@
+package toyfloat
+
+import "math"
+
+// Dot returns the dot product of two encoded vectors.
+// For types up to 8 bits it looks up pairwise products in a table,
+// which is built on the first call and shared by copies of the type.
+// It panics if the vectors have different lengths.
+func Dot(t *Type, a, b []uint16) float64 {
+	checkLengths(len(a), len(b))
+
+	r := 0.0
+	if t.length <= productTableMaxLength {
+		table := t.productTable()
+		for i := range a {
+			r += table[t.index(a[i])<<t.length|t.index(b[i])]
+		}
+		return r
+	}
+
+	table := t.decodeTable()
+	for i := range a {
+		r += table[t.index(a[i])] * table[t.index(b[i])]
+	}
+	return r
+}
+
+// L2Squared returns the squared Euclidean distance
+// between two encoded vectors.
+// It panics if the vectors have different lengths.
+func L2Squared(t *Type, a, b []uint16) float64 {
+	checkLengths(len(a), len(b))
+
+	table := t.decodeTable()
+	r := 0.0
+	for i := range a {
+		d := table[t.index(a[i])] - table[t.index(b[i])]
+		r += d * d
+	}
+	return r
+}
+
+// Cosine returns the cosine similarity of two encoded vectors.
+// It is NaN if one of them is zero.
+// It panics if the vectors have different lengths.
+func Cosine(t *Type, a, b []uint16) float64 {
+	return Dot(t, a, b) / math.Sqrt(Dot(t, a, a)*Dot(t, b, b))
+}
+
+// DotFloat32 returns the dot product of a float32 query
+// and an encoded vector.
+// It panics if the vectors have different lengths.
+func DotFloat32(t *Type, query []float32, b []uint16) float64 {
+	checkLengths(len(query), len(b))
+
+	table := t.decodeTable()
+	r := 0.0
+	for i := range query {
+		r += float64(query[i]) * table[t.index(b[i])]
+	}
+	return r
+}
+
+// L2SquaredFloat32 returns the squared Euclidean distance
+// between a float32 query and an encoded vector.
+// It panics if the vectors have different lengths.
+func L2SquaredFloat32(t *Type, query []float32, b []uint16) float64 {
+	checkLengths(len(query), len(b))
+
+	table := t.decodeTable()
+	r := 0.0
+	for i := range query {
+		d := float64(query[i]) - table[t.index(b[i])]
+		r += d * d
+	}
+	return r
+}
+
+// CosineFloat32 returns the cosine similarity of a float32 query
+// and an encoded vector. It is NaN if one of them is zero.
+// It panics if the vectors have different lengths.
+func CosineFloat32(t *Type, query []float32, b []uint16) float64 {
+	queryNorm := 0.0
+	for _, q := range query {
+		queryNorm += float64(q) * float64(q)
+	}
+	return DotFloat32(t, query, b) / math.Sqrt(queryNorm*Dot(t, b, b))
+}
+
+func checkLengths(a, b int) {
+	if a != b {
+		panic("toyfloat: vectors have different lengths")
+	}
+}
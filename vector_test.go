@@ -0,0 +1,112 @@
+package toyfloat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func getVectorSample(tf *Type, n int, seed int64) ([]uint16, []float64) {
+	r := rand.New(rand.NewSource(seed))
+	codes := make([]uint16, n)
+	values := make([]float64, n)
+	for i := range codes {
+		codes[i] = tf.Encode(2*r.Float64() - 1)
+		values[i] = tf.Decode(codes[i])
+	}
+	return codes, values
+}
+
+func TestVectorKernels(t *testing.T) {
+	const eps = 1e-9
+
+	for _, length := range []int{8, 12} {
+		tf := makeTypeX3(length, true, t)
+
+		a, va := getVectorSample(&tf, 768, 1)
+		b, vb := getVectorSample(&tf, 768, 2)
+
+		dot, aa, bb, l2 := 0.0, 0.0, 0.0, 0.0
+		for i := range va {
+			dot += va[i] * vb[i]
+			aa += va[i] * va[i]
+			bb += vb[i] * vb[i]
+			l2 += (va[i] - vb[i]) * (va[i] - vb[i])
+		}
+		cosine := dot / math.Sqrt(aa*bb)
+
+		if r := Dot(&tf, a, b); math.Abs(r-dot) > eps {
+			t.Fatalf("dot: %f != %f (%d bits)", r, dot, length)
+		}
+
+		if r := L2Squared(&tf, a, b); math.Abs(r-l2) > eps {
+			t.Fatalf("l2: %f != %f (%d bits)", r, l2, length)
+		}
+
+		if r := Cosine(&tf, a, b); math.Abs(r-cosine) > eps {
+			t.Fatalf("cosine: %f != %f (%d bits)", r, cosine, length)
+		}
+
+		query := make([]float32, len(va))
+		for i := range va {
+			query[i] = float32(va[i])
+		}
+
+		// float32 is not that precise.
+		const eps32 = 1e-5
+
+		if r := DotFloat32(&tf, query, b); math.Abs(r-dot) > eps32 {
+			t.Fatalf("dot32: %f != %f (%d bits)", r, dot, length)
+		}
+
+		if r := L2SquaredFloat32(&tf, query, b); math.Abs(r-l2) > eps32 {
+			t.Fatalf("l2 32: %f != %f (%d bits)", r, l2, length)
+		}
+
+		if r := CosineFloat32(&tf, query, b); math.Abs(r-cosine) > eps32 {
+			t.Fatalf("cosine32: %f != %f (%d bits)", r, cosine, length)
+		}
+	}
+}
+
+func TestVectorKernelsIgnoreExtraBits(t *testing.T) {
+	tf := makeTypeX3(8, true, t)
+	a, _ := getVectorSample(&tf, 100, 3)
+
+	b := make([]uint16, len(a))
+	for i := range a {
+		b[i] = a[i] | 0xAB00
+	}
+
+	if Dot(&tf, a, a) != Dot(&tf, b, b) {
+		t.Fatalf("extra bits are not ignored")
+	}
+}
+
+func TestVectorLengthMismatch(t *testing.T) {
+	tf := makeTypeX3(8, true, t)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("panic expected")
+		}
+	}()
+
+	Dot(&tf, make([]uint16, 3), make([]uint16, 4))
+}
+
+func BenchmarkDot8(b *testing.B) {
+	tf, e := NewTypeX3(8, true)
+	if e != nil {
+		b.Fatal(e)
+	}
+
+	x, _ := getVectorSample(&tf, 768, 1)
+	y, _ := getVectorSample(&tf, 768, 2)
+
+	r := 0.0
+	for i := 0; i < b.N; i++ {
+		r = Dot(&tf, x, y)
+	}
+	intResult = int(r)
+}
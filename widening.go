@@ -0,0 +1,117 @@
+package toyfloat
+
+// NarrowMode selects how Narrow drops the low mantissa bits.
+type NarrowMode int
+
+const (
+	// Truncate drops the bits, so the magnitude never grows.
+	// A value stored this way is a prefix of the wider code.
+	Truncate NarrowMode = iota
+
+	// RoundToNearest rounds the magnitude to the nearest code
+	// of the narrower type, ties away from zero.
+	// A carry out of the mantissa increments the exponent,
+	// which is still the nearest value, because the format is continuous.
+	RoundToNearest
+)
+
+// IsCompatible reports whether codes of one type can be converted
+// to the other by shifting the mantissa.
+// It requires the same base, exponent width, minX, signedness,
+// WithNonFinite, WithNegativeZero and WithTwosComplement.
+// Mantissa widths, layouts, tag policies and protection may differ,
+// since they only move the fields and set the extra bits.
+// Types with reserved codes are not compatible with any type.
+func IsCompatible(a, b *Type) bool {
+	return (a.xBase == b.xBase) &&
+		(a.xSize == b.xSize) &&
+		(a.minX == b.minX) &&
+		((a.minus == 0) == (b.minus == 0)) &&
+		(a.nonFinite == b.nonFinite) &&
+		(a.zeroMode == b.zeroMode) &&
+		(a.twos == b.twos) &&
+		(len(a.Reserved()) == 0) && (len(b.Reserved()) == 0)
+}
+
+// Widen converts a code to a compatible type with the same or wider mantissa.
+// It is exact: the decoded value does not change.
+// Extra most-significant bits of the argument are ignored.
+// The result has no tag, and has check bits, if the target type is protected.
+func Widen(code uint16, from, to *Type) (uint16, error) {
+	if !IsCompatible(from, to) {
+		return 0, ErrIncompatibleTypes
+	} else if to.mSize < from.mSize {
+		return 0, ErrMantissaWidth
+	}
+
+	shift := to.mSize - from.mSize
+	code = fromLayout(code, from)
+	magnitude := (code & from.bitmask) &^ from.minus
+
+	r := magnitude << shift
+	if isNonFinite(magnitude, from) {
+		r = to.infCode - (from.infCode - magnitude)
+	}
+
+	if isNegative(code, from.minus) {
+		r |= to.minus
+	}
+	return convertedCode(r, to), nil
+}
+
+// Narrow converts a code to a compatible type with the same
+// or narrower mantissa. Values above the maximum
+// of the target type after rounding are saturated.
+// Extra bits are handled as by Widen.
+func Narrow(code uint16, from, to *Type, mode NarrowMode) (uint16, error) {
+	if !IsCompatible(from, to) {
+		return 0, ErrIncompatibleTypes
+	} else if to.mSize > from.mSize {
+		return 0, ErrMantissaWidth
+	}
+
+	shift := from.mSize - to.mSize
+	code = fromLayout(code, from)
+	magnitude := (code & from.bitmask) &^ from.minus
+
+	var r uint16
+	if isNonFinite(magnitude, from) {
+		r = to.infCode - (from.infCode - magnitude)
+	} else {
+		r = narrowMagnitude(magnitude, shift, to.maxMagnitude, mode)
+	}
+
+	if isNegative(code, from.minus) && keepsSign(r, magnitude, to) {
+		r |= to.minus
+	}
+	return convertedCode(r, to), nil
+}
+
+func narrowMagnitude(magnitude uint16, shift uint8, max uint16, mode NarrowMode) uint16 {
+	wide := uint32(magnitude)
+	if (RoundToNearest == mode) && (shift > 0) {
+		wide += uint32(1) << (shift - 1)
+	}
+
+	// The exponent field follows the mantissa, so a carry is just
+	// a larger exponent. Beyond the last one there is nothing.
+	r := wide >> shift
+	if r > uint32(max) {
+		return max
+	}
+	return uint16(r)
+}
+
+// convertedCode expects a sign–magnitude "s x m" code without extra bits.
+func convertedCode(r uint16, to *Type) uint16 {
+	r = toLayout(r, to)
+	if NoProtection != to.protection {
+		return to.Protect(r)
+	}
+	return r
+}
+
+// isNonFinite reports whether the magnitude is infinity or NaN.
+func isNonFinite(magnitude uint16, t *Type) bool {
+	return t.nonFinite && (magnitude >= t.nanCode)
+}
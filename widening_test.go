@@ -0,0 +1,211 @@
+package toyfloat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIsCompatible(t *testing.T) {
+	tf12 := makeTypeX4(12, true, t)
+	tf16 := makeTypeX4(16, true, t)
+	tf16u := makeTypeX4(16, false, t)
+	tf16x3 := makeTypeX3(16, true, t)
+
+	tf16b3, err := NewType(16, 3, 4, -8, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsCompatible(&tf12, &tf16) || !IsCompatible(&tf16, &tf12) {
+		t.Fatalf("12 and 16 must be compatible")
+	}
+
+	if IsCompatible(&tf16, &tf16u) {
+		t.Fatalf("signed and unsigned must not be compatible")
+	}
+
+	if IsCompatible(&tf16, &tf16x3) {
+		t.Fatalf("different exponents must not be compatible")
+	}
+
+	if IsCompatible(&tf16, &tf16b3) {
+		t.Fatalf("different bases must not be compatible")
+	}
+
+	if _, err := Widen(0, &tf12, &tf16x3); err != ErrIncompatibleTypes {
+		t.Fatalf("ErrIncompatibleTypes expected, got %v", err)
+	}
+
+	if _, err := Narrow(0, &tf12, &tf16x3, Truncate); err != ErrIncompatibleTypes {
+		t.Fatalf("ErrIncompatibleTypes expected, got %v", err)
+	}
+
+	if _, err := Widen(0, &tf16, &tf12); err != ErrMantissaWidth {
+		t.Fatalf("ErrMantissaWidth expected: narrowing with Widen, got %v", err)
+	}
+
+	if _, err := Narrow(0, &tf12, &tf16, Truncate); err != ErrMantissaWidth {
+		t.Fatalf("ErrMantissaWidth expected: widening with Narrow, got %v", err)
+	}
+}
+
+func TestWidenIsExact(t *testing.T) {
+	pairs := []struct {
+		xBase, xSize uint8
+		minX         int
+		signed       bool
+	}{
+		{2, 4, -8, true},
+		{2, 4, -8, false},
+		{2, 3, -6, true},
+		{3, 2, -3, true},
+		{10, 3, -2, true},
+	}
+
+	for _, p := range pairs {
+		from, err := NewType(p.xSize+4, p.xBase, p.xSize, p.minX, p.signed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		to, err := NewType(16, p.xBase, p.xSize, p.minX, p.signed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i <= int(from.bitmask); i++ {
+			code := uint16(i)
+			wide, err := Widen(code, &from, &to)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			a := from.Decode(code)
+			b := to.Decode(wide)
+			if math.Abs(a-b) > 1e-12*math.Abs(a) {
+				t.Fatalf("0x%X -> 0x%X: %f != %f (base %d)",
+					code, wide, b, a, p.xBase)
+			}
+
+			narrow, err := Narrow(wide, &to, &from, Truncate)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if narrow != code {
+				t.Fatalf("0x%X -> 0x%X -> 0x%X", code, wide, narrow)
+			}
+		}
+	}
+}
+
+func TestNarrowTruncate(t *testing.T) {
+	tf12 := makeTypeX4(12, true, t)
+	tf16 := makeTypeX4(16, true, t)
+
+	for i := 0; i <= int(tf16.bitmask); i++ {
+		code := uint16(i)
+		narrow, err := Narrow(code, &tf16, &tf12, Truncate)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if narrow&^tf12.bitmask != 0 {
+			t.Fatalf("0x%X has extra bits", narrow)
+		}
+
+		original := tf16.Decode(code)
+		result := tf12.Decode(narrow)
+
+		if math.Abs(result) > math.Abs(original) {
+			t.Fatalf("|%f| > |%f|", result, original)
+		}
+
+		if math.Signbit(result) != math.Signbit(original) {
+			t.Fatalf("the sign changed: %f, %f", result, original)
+		}
+	}
+}
+
+func TestNarrowRoundToNearest(t *testing.T) {
+	tf12 := makeTypeX4(12, true, t)
+	tf16 := makeTypeX4(16, true, t)
+
+	const half = 0x8
+
+	for i := 0; i <= int(tf16.bitmask); i++ {
+		code := uint16(i)
+		narrow, err := Narrow(code, &tf16, &tf12, RoundToNearest)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if code&0xF == half {
+			// Encode rounds ties down, Narrow rounds them up.
+			continue
+		}
+
+		// Decoded values are compared because of -0.
+		expected := tf12.Encode(tf16.Decode(code))
+		if tf12.Decode(narrow) != tf12.Decode(expected) {
+			t.Fatalf("0x%X -> 0x%X, expected 0x%X", code, narrow, expected)
+		}
+	}
+
+	maxCode := tf16.Encode(tf16.MaxValue())
+	narrow, err := Narrow(maxCode, &tf16, &tf12, RoundToNearest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tf12.Decode(narrow) != tf12.MaxValue() {
+		t.Fatalf("maximum is not saturated: 0x%X", narrow)
+	}
+}
+
+func TestConvertProtected(t *testing.T) {
+	tf8 := makeTypeX4(8, true, t)
+	tf12 := makeTypeX4(12, true, t, WithProtection(CRC4))
+	tf14 := makeTypeX4(14, true, t, WithProtection(Parity), WithLayout(Layout{Order: OrderXSM}))
+
+	for i := 0; i <= int(tf8.bitmask); i++ {
+		code := uint16(i)
+
+		wide, err := Widen(code, &tf8, &tf12)
+		if err != nil {
+			t.Fatal(err)
+		} else if _, err := tf12.Verify(wide); err != nil {
+			t.Fatalf("0x%X -> 0x%X: %v", code, wide, err)
+		}
+
+		wider, err := Widen(wide, &tf12, &tf14)
+		if err != nil {
+			t.Fatal(err)
+		} else if _, err := tf14.Verify(wider); err != nil {
+			t.Fatalf("0x%X -> 0x%X: %v", wide, wider, err)
+		} else if a, b := tf8.Decode(code), tf14.Decode(wider); a != b {
+			t.Fatalf("0x%X -> 0x%X: %f != %f", code, wider, b, a)
+		}
+
+		narrow, err := Narrow(wider, &tf14, &tf12, Truncate)
+		if err != nil {
+			t.Fatal(err)
+		} else if narrow != wide {
+			t.Fatalf("0x%X -> 0x%X != 0x%X", wider, narrow, wide)
+		}
+	}
+}
+
+func TestNarrowIgnoresMostSignificantBits(t *testing.T) {
+	tf12 := makeTypeX4(12, true, t)
+	tf8 := makeTypeX4(8, true, t)
+
+	for i := 0; i <= int(tf12.bitmask); i++ {
+		code := uint16(i)
+		a, _ := Narrow(code, &tf12, &tf8, RoundToNearest)
+		b, _ := Narrow(code|0xF000, &tf12, &tf8, RoundToNearest)
+		if a != b {
+			t.Fatalf("0x%X != 0x%X", a, b)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package toyfloat
+
+// NegativeZeroMode selects what a signed type does with the code of -0,
+// which is the sign bit alone.
+type NegativeZeroMode uint8
+
+const (
+	// KeepNegativeZero is the default. Small negative numbers
+	// are encoded as -0, and the comparable form places -0 right before +0.
+	KeepNegativeZero NegativeZeroMode = iota
+
+	// CanonicalZero makes +0 the only zero. Encode never produces -0,
+	// Decode, Abs and the delta functions treat it as +0,
+	// so raw codes of equal values are equal.
+	CanonicalZero
+
+	// NegativeZeroMarker makes -0 a marker of a missing value.
+	// Encode never produces it, Decode returns NaN for it,
+	// and the comparable form places it below all numbers.
+	NegativeZeroMarker
+)
+
+// WithNegativeZero selects the mode for the code of -0.
+// Unsigned types have no such code, so they accept only KeepNegativeZero.
+//
+// Under either of the other modes, the comparable form of negative numbers
+// is shifted by one, so that it is strictly monotone:
+// each comparable value but zero means a different number.
+// Comparable zero is the marker, or is not used with CanonicalZero.
+func WithNegativeZero(mode NegativeZeroMode) Option {
+	return func(p *Params) {
+		p.NegativeZero = mode
+	}
+}
+
+// IsNegativeZero reports whether the code is -0.
+// For NegativeZeroMarker types, it is the marker.
+// Extra most-significant bits are ignored.
+func (t *Type) IsNegativeZero(x uint16) bool {
+	return (0 != t.minus) && (fromLayout(x, t)&t.bitmask == t.minus)
+}
+
+// Marker returns the code of a missing value,
+// which is -0 for NegativeZeroMarker types.
+// Other types have no marker.
+func (t *Type) Marker() (uint16, bool) {
+	if NegativeZeroMarker != t.zeroMode {
+		return 0, false
+	}
+	return toLayout(t.minus, t), true
+}
+
+// String returns the name of the mode, as in a spec.
+func (m NegativeZeroMode) String() string {
+	switch m {
+	case KeepNegativeZero:
+		return "keep"
+	case CanonicalZero:
+		return "canonical"
+	case NegativeZeroMarker:
+		return "marker"
+	}
+	return "invalid"
+}
+
+// ----------------
+
+func checkNegativeZero(mode NegativeZeroMode, signed bool) error {
+	if mode > NegativeZeroMarker {
+		return ErrInvalidNegativeZero
+	} else if !signed && (KeepNegativeZero != mode) {
+		return ErrInvalidNegativeZero
+	}
+	return nil
+}
+
+// keepsSign reports whether a negative code with the magnitude
+// narrowed from the original one keeps its sign bit.
+func keepsSign(magnitude, original uint16, t *Type) bool {
+	if 0 != magnitude {
+		return true
+	}
+
+	switch t.zeroMode {
+	case CanonicalZero:
+		return false
+	case NegativeZeroMarker:
+		// Only the marker stays the marker.
+		return 0 == original
+	}
+	return true
+}
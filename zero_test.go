@@ -0,0 +1,276 @@
+package toyfloat
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNegativeZeroEncoding(t *testing.T) {
+	keep := makeTypeX4(12, true, t, WithNegativeZero(KeepNegativeZero))
+	if keep != makeTypeX4(12, true, t) {
+		t.Fatalf("KeepNegativeZero is the default")
+	}
+
+	if !keep.IsNegativeZero(keep.Encode(-1e-9)) {
+		t.Fatalf("a small negative number must be -0 by default")
+	}
+
+	for _, mode := range []NegativeZeroMode{CanonicalZero, NegativeZeroMarker} {
+		tf := makeTypeX4(12, true, t, WithNegativeZero(mode))
+
+		for _, v := range []float64{-1e-9, math.Copysign(0, -1), 0, 1e-9} {
+			if code := tf.Encode(v); code != 0 {
+				t.Fatalf("%s: %g -> 0x%X", mode, v, code)
+			}
+		}
+
+		for f := -300.0; f <= 300.0; f += 0.01 {
+			code := tf.Encode(f)
+			if tf.IsNegativeZero(code) {
+				t.Fatalf("%s: %f -> -0", mode, f)
+			}
+
+			if expected := keep.Encode(f); !keep.IsNegativeZero(expected) &&
+				(code != expected) {
+
+				t.Fatalf("%s: %f -> 0x%X, expected 0x%X", mode, f, code, expected)
+			}
+		}
+	}
+}
+
+func TestNegativeZeroDecoding(t *testing.T) {
+	canonical := makeTypeX4(12, true, t, WithNegativeZero(CanonicalZero))
+	v := canonical.Decode(0xF800)
+	if (v != 0) || math.Signbit(v) {
+		t.Fatalf("%g is not +0", v)
+	}
+
+	if abs := canonical.Abs(0x800); abs != 0 {
+		t.Fatalf("0x%X != 0", abs)
+	}
+
+	if marker, ok := canonical.Marker(); ok {
+		t.Fatalf("unexpected marker 0x%X", marker)
+	}
+
+	tf := makeTypeX4(12, true, t, WithNegativeZero(NegativeZeroMarker))
+	marker, ok := tf.Marker()
+	if !ok || (marker != 0x800) || !tf.IsNegativeZero(marker) {
+		t.Fatalf("0x%X, %t", marker, ok)
+	}
+
+	if !math.IsNaN(tf.Decode(marker)) {
+		t.Fatalf("the marker must be NaN")
+	}
+
+	if abs := tf.Abs(marker); abs != marker {
+		t.Fatalf("0x%X != 0x%X", abs, marker)
+	}
+
+	if abs := tf.Abs(tf.Encode(-2)); abs != tf.Encode(2) {
+		t.Fatalf("0x%X != 0x%X", abs, tf.Encode(2))
+	}
+}
+
+func TestNegativeZeroComparable(t *testing.T) {
+	for _, mode := range []NegativeZeroMode{CanonicalZero, NegativeZeroMarker} {
+		for _, length := range []int{6, 9, 12, 16} {
+			tf := makeTypeX4(length, true, t, WithNegativeZero(mode))
+
+			last := math.Inf(-1)
+			for i := 1; i <= int(tf.bitmask); i++ {
+				code := tf.FromComparable(uint16(i)) & tf.bitmask
+				if tf.IsNegativeZero(code) {
+					t.Fatalf("%s, %d: 0x%X -> -0", mode, length, i)
+				} else if c := tf.ToComparable(code); c != uint16(i) {
+					t.Fatalf("%s, %d: 0x%X -> 0x%X -> 0x%X", mode, length, i, code, c)
+				}
+
+				// Strictly monotone.
+				v := tf.Decode(code)
+				if v <= last {
+					t.Fatalf("%s, %d: 0x%X: %g <= %g", mode, length, i, v, last)
+				}
+				last = v
+			}
+		}
+	}
+
+	canonical := makeTypeX4(12, true, t, WithNegativeZero(CanonicalZero))
+	if c := canonical.ToComparable(0x800); c != canonical.ToComparable(0) {
+		t.Fatalf("-0 must be the same as +0, 0x%X", c)
+	}
+
+	if code := canonical.FromComparable(0) & canonical.bitmask; code != 0xFFF {
+		t.Fatalf("comparable zero must be the lowest value, 0x%X", code)
+	}
+
+	tf := makeTypeX4(12, true, t, WithNegativeZero(NegativeZeroMarker))
+	if c := tf.ToComparable(0xF800); c != 0 {
+		t.Fatalf("the marker must be the lowest, 0x%X", c)
+	}
+
+	if code := tf.FromComparable(0); code != 0x800 {
+		t.Fatalf("0x%X is not the marker", code)
+	}
+
+	codes := []uint16{tf.Encode(1), 0x800, tf.Encode(-1), 0}
+	SortCodes(&tf, codes)
+	if (codes[0] != 0x800) || (codes[1] != tf.Encode(-1)) || (codes[2] != 0) {
+		t.Fatalf("wrong order %X", codes)
+	}
+
+	if i := SearchCodes(&tf, codes, 0); i != 2 {
+		t.Fatalf("%d != 2", i)
+	}
+}
+
+func TestNegativeZeroDelta(t *testing.T) {
+	canonical := makeTypeX4(12, true, t, WithNegativeZero(CanonicalZero))
+	if d := canonical.GetIntegerDelta(0x800, 0); d != 0 {
+		t.Fatalf("%d != 0", d)
+	}
+
+	if d := canonical.GetIntegerDelta(0x801, 0); d != 1 {
+		t.Fatalf("%d != 1", d)
+	}
+
+	if code := canonical.UseIntegerDelta(0x801, 1); code != 0 {
+		t.Fatalf("0x%X != 0", code)
+	}
+
+	if code := canonical.UseIntegerDelta(0, -10000) & 0xFFF; code != 0xFFF {
+		t.Fatalf("0x%X != 0xFFF", code)
+	}
+
+	tf := makeTypeX4(12, true, t, WithNegativeZero(NegativeZeroMarker))
+	minimum := tf.Encode(tf.MinValue())
+
+	if d := tf.GetIntegerDelta(minimum, 0x800); d != -1 {
+		t.Fatalf("%d != -1", d)
+	}
+
+	if code := tf.UseIntegerDelta(minimum, -1); code != 0x800 {
+		t.Fatalf("0x%X is not the marker", code)
+	}
+
+	// Saturation does not make markers.
+	if code := tf.UseIntegerDelta(0, -10000) & 0xFFF; code != minimum {
+		t.Fatalf("0x%X != 0x%X", code, minimum)
+	}
+
+	for _, mode := range []NegativeZeroMode{CanonicalZero, NegativeZeroMarker} {
+		tf := makeTypeX4(12, true, t, WithNegativeZero(mode))
+
+		last := tf.Encode(-256)
+		for x := -256.0; x <= 256.0; x += 0.01 {
+			code := tf.Encode(x)
+			result := tf.UseIntegerDelta(last, tf.GetIntegerDelta(last, code))
+
+			if result&tf.bitmask != code {
+				t.Fatalf("%s: 0x%X -> 0x%X, expected 0x%X", mode, last, result, code)
+			}
+			last = code
+		}
+	}
+}
+
+func TestNegativeZeroNarrow(t *testing.T) {
+	for _, mode := range []NegativeZeroMode{CanonicalZero, NegativeZeroMarker} {
+		wide := makeTypeX4(16, true, t, WithNegativeZero(mode))
+		narrow := makeTypeX4(12, true, t, WithNegativeZero(mode))
+
+		// Its magnitude is 1, which is truncated to 0.
+		code, err := Narrow(0x8001, &wide, &narrow, Truncate)
+		if err != nil {
+			t.Fatal(err)
+		} else if code != 0 {
+			t.Fatalf("%s: 0x%X != 0", mode, code)
+		}
+
+		code, err = Narrow(0x8000, &wide, &narrow, Truncate)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := uint16(0x800)
+		if CanonicalZero == mode {
+			expected = 0
+		}
+
+		if code != expected {
+			t.Fatalf("%s: 0x%X != 0x%X", mode, code, expected)
+		}
+	}
+
+	keep := makeTypeX4(16, true, t)
+	canonical := makeTypeX4(12, true, t, WithNegativeZero(CanonicalZero))
+	if IsCompatible(&keep, &canonical) {
+		t.Fatalf("different modes must not be compatible")
+	}
+}
+
+func TestNegativeZeroParams(t *testing.T) {
+	tests := []struct {
+		mode NegativeZeroMode
+		spec string
+	}{
+		{KeepNegativeZero, "s12x4b2m-8"},
+		{CanonicalZero, "s12x4b2m-8+zero=canonical"},
+		{NegativeZeroMarker, "s12x4b2m-8+zero=marker"},
+	}
+
+	for _, tt := range tests {
+		tf := makeTypeX4(12, true, t, WithNegativeZero(tt.mode))
+		if tf.Spec() != tt.spec {
+			t.Fatalf("%s != %s", tf.Spec(), tt.spec)
+		}
+
+		parsed, err := ParseType(tt.spec)
+		if err != nil {
+			t.Fatal(err)
+		} else if parsed != tf {
+			t.Fatalf("%s: different types", tt.spec)
+		}
+
+		if tf.Params().NegativeZero != tt.mode {
+			t.Fatalf("%s: %s", tt.spec, tf.Params().NegativeZero)
+		}
+	}
+
+	for _, bad := range []string{
+		"s12x4b2m-8+zero=keep",
+		"s12x4b2m-8+zero=",
+		"u12x4b2m-8+zero=canonical"} {
+
+		if _, err := ParseType(bad); err == nil {
+			t.Fatalf("%s: error expected", bad)
+		}
+	}
+
+	_, err := NewTypeX4(12, false, WithNegativeZero(NegativeZeroMarker))
+	if !errors.Is(err, ErrInvalidNegativeZero) {
+		t.Fatalf("ErrInvalidNegativeZero expected, got %v", err)
+	}
+
+	_, err = NewTypeX4(12, true, WithNegativeZero(NegativeZeroMarker+1))
+	if !errors.Is(err, ErrInvalidNegativeZero) {
+		t.Fatalf("ErrInvalidNegativeZero expected, got %v", err)
+	}
+
+	tf, err := NewTypeX4(12, true,
+		WithNegativeZero(CanonicalZero), WithReserved(0x801, 0x1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := tf.GetIntegerDelta(0x802, 0x2); d != 2 {
+		t.Fatalf("%d != 2", d)
+	}
+
+	if code := tf.Encode(-1e-9); code != 0 {
+		t.Fatalf("0x%X != 0", code)
+	}
+}